@@ -192,6 +192,46 @@ func handleAddUser(db *sql.DB) http.HandlerFunc {
 	}
 }
 
+func handleDeleteUser(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		idParam := r.URL.Query().Get("id")
+		if idParam == "" {
+			http.Error(w, "User ID is required", http.StatusBadRequest)
+			return
+		}
+		targetID, err := strconv.Atoi(idParam)
+		if err != nil {
+			http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+			return
+		}
+
+		result, err := db.Exec("DELETE FROM users WHERE id = ?", targetID)
+		if err != nil {
+			log.Printf("Error deleting user with ID %d: %v", targetID, err)
+			http.Error(w, "Internal server error (DB exec)", http.StatusInternalServerError)
+			return
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting rows affected for ID %d: %v", targetID, err)
+			http.Error(w, "Internal server error (rows affected)", http.StatusInternalServerError)
+			return
+		}
+		if rowsAffected == 0 {
+			http.Error(w, fmt.Sprintf("User with ID %d not found", targetID), http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent) // 204 No Content status
+	}
+}
+
 func main() {
 	// dbFileName where SQLite data is stored
 	const dbFileName = "users.db"
@@ -204,11 +244,17 @@ func main() {
 
 	// ...existing code...
 	mux := http.NewServeMux()
+	// Build each handler once rather than on every request
+	getUsers := handleGetUsers(db)
+	addUser := handleAddUser(db)
+	deleteUser := handleDeleteUser(db)
 	usersHandlerFunc := func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
-			handleGetUsers(db)(w, r)
+			getUsers(w, r)
 		} else if r.Method == http.MethodPost {
-			handleAddUser(db)(w, r) // Your existing handleAddUser logic
+			addUser(w, r)
+		} else if r.Method == http.MethodDelete {
+			deleteUser(w, r)
 		} else {
 			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
 		}
@@ -267,3 +267,95 @@ func TestHandleGetUsers(t *testing.T) {
 		}
 	})
 }
+
+// TestHandleDeleteUser tests the handleDeleteUser handler.
+func TestHandleDeleteUser(t *testing.T) {
+	testDB, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	handler := handleDeleteUser(testDB)
+
+	// Pre-populate data
+	res, err := testDB.Exec("INSERT INTO users (name, email, age) VALUES ('Alice', 'alice@example.com', 28)")
+	if err != nil {
+		t.Fatalf("DB insert failed: %v", err)
+	}
+	aliceID, _ := res.LastInsertId()
+
+	t.Run("Positive case - delete user successfully", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", fmt.Sprintf("/users?id=%d", aliceID), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusNoContent {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNoContent)
+		}
+
+		var count int
+		if err := testDB.QueryRow("SELECT COUNT(*) FROM users WHERE id = ?", aliceID).Scan(&count); err != nil {
+			t.Fatalf("Failed to query DB: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("Expected user %d to be deleted, found %d rows", aliceID, count)
+		}
+	})
+
+	t.Run("Negative case - delete user, ID not found", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", fmt.Sprintf("/users?id=%d", aliceID), nil) // Already deleted
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusNotFound {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("Negative case - missing ID", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/users", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+		expectedBody := "User ID is required\n"
+		if rr.Body.String() != expectedBody {
+			t.Errorf("handler returned unexpected body: got %q want %q", rr.Body.String(), expectedBody)
+		}
+	})
+
+	t.Run("Negative case - invalid ID format", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/users?id=abc", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Negative case - wrong method", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/users?id=1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusMethodNotAllowed {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
+		}
+	})
+}
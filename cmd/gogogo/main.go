@@ -0,0 +1,258 @@
+// Command gogogo is a single entrypoint for the datarizer tools. Each subcommand
+// parses its own flags:
+//
+//	gogogo ingest -url http://localhost:8000/users/ -out tmp/users.jsonl
+//	gogogo write -input students.json -jsonl tmp/students.jsonl -parquet tmp/students.parquet
+//	gogogo serve -file tmp/users.jsonl -addr :8080
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kagenihisomi/datarizer/datarizer"
+	"github.com/kagenihisomi/gogogo/internal/ingest"
+)
+
+// errUsage reports invalid command-line usage, which exits with status 2.
+var errUsage = errors.New("invalid usage")
+
+// command is a gogogo subcommand. run receives the arguments following the subcommand name.
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string, stdout, stderr io.Writer) error
+}
+
+var commands = []command{
+	{name: "ingest", summary: "fetch records from a skip/limit paginated JSON API into a JSONL file", run: runIngest},
+	{name: "write", summary: "parse a JSON array of students into JSONL and Parquet files", run: runWrite},
+	{name: "serve", summary: "serve the records of a JSONL file over HTTP", run: runServe},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run dispatches args to the named subcommand and returns the process exit status.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(ctx, args[1:], stdout, stderr)
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(stderr, "gogogo %s: %v\n", name, err)
+			return 2
+		default:
+			fmt.Fprintf(stderr, "gogogo %s: %v\n", name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stderr, "gogogo: unknown subcommand %q\n", name)
+	printUsage(stderr)
+	return 2
+}
+
+// printUsage lists the available subcommands.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: gogogo <subcommand> [flags]")
+	fmt.Fprintln(w, "\nSubcommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nRun 'gogogo <subcommand> -help' for the flags of a subcommand.")
+}
+
+// newFlagSet returns a flag set for the named subcommand that reports errors instead of exiting.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("gogogo "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parseFlags parses args into fs, rejecting positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected arguments %v", errUsage, fs.Args())
+	}
+	return nil
+}
+
+// runIngest fetches every page of a JSON array endpoint with the ingest package, as
+// cmd/ingest does, and writes the records as JSONL or JSON.
+func runIngest(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("ingest", stderr)
+	endpoint := fs.String("url", "http://localhost:8000/users/", "endpoint returning a JSON array, paginated with skip and limit")
+	out := fs.String("out", "tmp/records.jsonl", "output file path")
+	format := fs.String("format", "jsonl", "output format: json or jsonl")
+	pageSize := fs.Int("page-size", ingest.DefaultPageSize, "number of records requested per page")
+	retries := fs.Int("retries", 5, "maximum number of retries per request")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *pageSize <= 0 {
+		return fmt.Errorf("%w: -page-size must be positive, got %d", errUsage, *pageSize)
+	}
+	if *format != "json" && *format != "jsonl" {
+		return fmt.Errorf("%w: unknown -format %q (expected json or jsonl)", errUsage, *format)
+	}
+	if _, err := url.ParseRequestURI(*endpoint); err != nil {
+		return fmt.Errorf("%w: invalid -url %q: %v", errUsage, *endpoint, err)
+	}
+
+	clientOpts := ingest.DefaultIngestOptions()
+	clientOpts.RetryMax = *retries
+	resource := &ingest.ResourceClient[map[string]interface{}]{
+		Client:   ingest.NewIngestClient(clientOpts),
+		BaseURL:  *endpoint,
+		PageSize: *pageSize,
+	}
+	records, err := resource.FetchAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := ingest.WriteRecords(records, ingest.WriteOptions{Path: *out, Format: *format}, time.Now()); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Wrote %d records to %s\n", len(records), *out)
+	return nil
+}
+
+// runWrite parses a JSON array of students, enriching each with RecordInfo, and writes
+// the records to the requested JSONL and Parquet files.
+func runWrite(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("write", stderr)
+	input := fs.String("input", "-", "JSON array of students to read, - for stdin")
+	source := fs.String("source", "", "SourceInfo recorded on each record (default the input path)")
+	jsonlPath := fs.String("jsonl", "", "output JSONL file path")
+	parquetPath := fs.String("parquet", "", "output Parquet file path")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *jsonlPath == "" && *parquetPath == "" {
+		return fmt.Errorf("%w: at least one of -jsonl or -parquet is required", errUsage)
+	}
+
+	var data []byte
+	var err error
+	if *input == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*input)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read input '%s': %w", *input, err)
+	}
+	if *source == "" {
+		*source = *input
+	}
+
+	parser := datarizer.BaseSchemaParser[datarizer.Student]{}
+	students, err := parser.ParseFromJsonArray(data, *source)
+	if err != nil {
+		return fmt.Errorf("failed to parse records: %w", err)
+	}
+	df := datarizer.CreateDataFrame(students)
+
+	if *jsonlPath != "" {
+		if err := df.WriteToJSONL(*jsonlPath); err != nil {
+			return err
+		}
+	}
+	if *parquetPath != "" {
+		if err := df.WriteToLocalParquet(*parquetPath); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "Wrote %d records\n", len(students))
+	return nil
+}
+
+// runServe serves the records of a JSONL file as JSONL, re-reading the file on every request.
+// It shares no handlers with cmd/api, which serves the users table of its SQLite database;
+// serve exposes files such as the output of ingest instead.
+func runServe(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("serve", stderr)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	file := fs.String("file", "", "JSONL file whose records are served")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("%w: -file is required", errUsage)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/records", handleRecords(*file))
+	server := &http.Server{Addr: *addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Serving %s at http://%s/records", *file, *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
+
+// handleRecords writes the records of the JSONL file at path as an application/x-ndjson response.
+func handleRecords(path string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		df, err := datarizer.ReadFromJSONL[map[string]interface{}](path)
+		if err != nil {
+			log.Printf("Failed to read %s: %v", path, err)
+			http.Error(w, "Failed to read records", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", datarizer.JSONLContentType)
+		if err := datarizer.WriteJSONLToWriter(w, df.Records); err != nil {
+			log.Printf("Failed to write records: %v", err)
+		}
+	}
+}
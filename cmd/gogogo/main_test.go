@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kagenihisomi/datarizer/datarizer"
+)
+
+// TestRunUsage tests subcommand help and usage errors
+func TestRunUsage(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantStatus int
+		wantStderr string
+	}{
+		{name: "no subcommand", args: nil, wantStatus: 2, wantStderr: "Usage: gogogo"},
+		{name: "unknown subcommand", args: []string{"frobnicate"}, wantStatus: 2, wantStderr: `unknown subcommand "frobnicate"`},
+		{name: "write help", args: []string{"write", "--help"}, wantStatus: 0, wantStderr: "-parquet"},
+		{name: "unknown flag", args: []string{"write", "-nope"}, wantStatus: 2, wantStderr: "flag provided but not defined"},
+		{name: "missing outputs", args: []string{"write", "-input", "students.json"}, wantStatus: 2, wantStderr: "at least one of -jsonl or -parquet"},
+		{name: "serve without file", args: []string{"serve"}, wantStatus: 2, wantStderr: "-file is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(context.Background(), tt.args, &stdout, &stderr)
+			if status != tt.wantStatus {
+				t.Errorf("Exit status mismatch: expected=%d, got=%d (stderr: %s)", tt.wantStatus, status, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("Expected stderr to contain %q, got %q", tt.wantStderr, stderr.String())
+			}
+		})
+	}
+}
+
+// TestRunWrite tests writing JSONL and Parquet output from a JSON array
+func TestRunWrite(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "students.json")
+	data := `[{"Name": "Alice", "Age": 22, "Id": 1001}, {"Name": "Bob", "Age": 23, "Id": 1002}]`
+	if err := os.WriteFile(input, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	jsonlPath := filepath.Join(dir, "out", "students.jsonl")
+	parquetPath := filepath.Join(dir, "out", "students.parquet")
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"write", "-input", input, "-jsonl", jsonlPath, "-parquet", parquetPath}
+	if status := run(context.Background(), args, &stdout, &stderr); status != 0 {
+		t.Fatalf("write failed with status %d: %s", status, stderr.String())
+	}
+
+	df, err := datarizer.ReadFromJSONL[datarizer.Student](jsonlPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSONL output: %v", err)
+	}
+	if len(df.Records) != 2 || df.Records[1].Name != "Bob" || df.Records[0].RecordInfo.SourceInfo != input {
+		t.Errorf("Unexpected JSONL records: %+v", df.Records)
+	}
+	if _, err := os.Stat(parquetPath); err != nil {
+		t.Errorf("Parquet output missing: %v", err)
+	}
+}
+
+// TestRunIngest tests fetching every page of an endpoint into a JSONL file
+func TestRunIngest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		var page []map[string]interface{}
+		for i := skip; i < 5 && len(page) < 2; i++ {
+			page = append(page, map[string]interface{}{"id": i})
+		}
+		if page == nil {
+			page = []map[string]interface{}{}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	out := filepath.Join(t.TempDir(), "records.jsonl")
+	var stdout, stderr bytes.Buffer
+	args := []string{"ingest", "-url", server.URL, "-out", out, "-page-size", "2"}
+	if status := run(context.Background(), args, &stdout, &stderr); status != 0 {
+		t.Fatalf("ingest failed with status %d: %s", status, stderr.String())
+	}
+
+	df, err := datarizer.ReadFromJSONL[map[string]interface{}](out)
+	if err != nil {
+		t.Fatalf("Failed to read JSONL output: %v", err)
+	}
+	if len(df.Records) != 5 {
+		t.Errorf("Expected 5 records, got %d", len(df.Records))
+	}
+}
+
+// TestHandleRecords tests serving the records of a JSONL file
+func TestHandleRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.jsonl")
+	if err := os.WriteFile(path, []byte("{\"id\":1}\n{\"id\":2}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write records: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	handleRecords(path)(rec, httptest.NewRequest(http.MethodGet, "/records", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != datarizer.JSONLContentType {
+		t.Errorf("Content-Type mismatch: expected=%s, got=%s", datarizer.JSONLContentType, ct)
+	}
+	if got := strings.Count(rec.Body.String(), "\n"); got != 2 {
+		t.Errorf("Expected 2 lines, got %d: %q", got, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handleRecords(path)(rec, httptest.NewRequest(http.MethodPost, "/records", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for POST, got %d", rec.Code)
+	}
+}
@@ -2,21 +2,22 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"log"
-	"math/rand"
-	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"slices"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
-	"github.com/xitongsys/parquet-go-source/local" //
-	"github.com/xitongsys/parquet-go/parquet"      // Added for compression codecs
-	"github.com/xitongsys/parquet-go/writer"       // For simpler Parquet writing
+	"github.com/kagenihisomi/gogogo/internal/ingest"
+	"github.com/xitongsys/parquet-go/parquet" // Added for compression codecs
 )
 
 // User struct to match the FastAPI UserResponse
@@ -28,213 +29,199 @@ type User struct {
 }
 
 const (
-	baseURL          = "http://localhost:8000/users/"
-	defaultPageLimit = 50 // Number of users to request per page (FastAPI max is 100)
-	maxRetries       = 5
-	initialBackoff   = 1 * time.Second
-	maxBackoff       = 30 * time.Second
-	requestTimeout   = 15 * time.Second // Timeout for each individual HTTP request attempt
-	totalJobTimeout  = 5 * time.Minute  // Optional: A total timeout for the entire ETL job
+	defaultBaseURL     = "http://localhost:8000/users/"
+	defaultPageLimit   = 50              // Number of users to request per page (FastAPI max is 100)
+	defaultPageTimeout = 0               // Timeout for one page request including all of its retries; disabled by default
+	totalJobTimeout    = 5 * time.Minute // Optional: A total timeout for the entire ETL job
 )
 
-// sharedRetryableClient is a shared client for connection reuse and retries.
-var sharedRetryableClient *retryablehttp.Client
-
-func init() {
-	// Seed the global random number generator (good practice, though retryablehttp handles its own jitter)
-	rand.New(rand.NewSource(42))
-
-	client := retryablehttp.NewClient()
-	client.RetryMax = maxRetries
-	client.RetryWaitMin = initialBackoff
-	client.RetryWaitMax = maxBackoff
-	// The client.HTTPClient is a standard *http.Client.
-	// We set its timeout for individual attempts made by the retryablehttp client.
-	client.HTTPClient.Timeout = requestTimeout
-
-	// Configure the logger for retryablehttp.
-	// Set to nil or a logger that writes to io.Discard to suppress verbose logging from the library.
-	// If you need to debug retry attempts, you can set it to log.Default() or a custom logger.
-	client.Logger = nil // Suppress verbose library logging by default
-
-	// The DefaultRetryPolicy is generally sufficient and covers common retry scenarios
-	// like network errors, 429s, and 5xx server errors.
-	// client.CheckRetry = retryablehttp.DefaultRetryPolicy (this is the default)
-
-	sharedRetryableClient = client
-}
-
 func main() {
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid arguments: %v", err)
+	}
+
 	log.Println("Starting ETL process to fetch all users...")
 
-	// Overall context for the entire ETL job
-	ctx, cancelJob := context.WithTimeout(context.Background(), totalJobTimeout)
+	// Overall context for the entire ETL job, cancelled early on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancelJob := context.WithTimeout(ctx, totalJobTimeout)
 	defer cancelJob()
 
-	allUsers, err := fetchAllUsers(ctx)
-	if err != nil {
-		log.Fatalf("ETL process failed: %v", err)
-	}
+	stats := &ingest.RetryStats{}
+	clientOpts := ingest.DefaultIngestOptions()
+	clientOpts.Verbose = opts.Verbose
+	clientOpts.Stats = stats
+	client := ingest.NewIngestClient(clientOpts)
 
-	log.Printf("Successfully fetched %d users.\n", len(allUsers))
-
-	// Example: Writing to JSON
-	jsonFilePath := "tmp/users.json"
-	if err := writeUsersToJSON(allUsers, jsonFilePath); err != nil {
-		log.Fatalf("Failed to write users to JSON: %v", err)
+	allUsers, err := fetchAllUsers(ctx, client, opts, stats)
+	if err != nil {
+		if !opts.SavePartial || !errors.Is(err, context.Canceled) {
+			log.Fatalf("ETL process failed: %v", err)
+		}
+		log.Printf("ETL process interrupted, saving %d users fetched so far: %v", len(allUsers), err)
+	} else {
+		log.Printf("Successfully fetched %d users.\n", len(allUsers))
 	}
-	log.Printf("Successfully wrote users to %s\n", jsonFilePath)
 
-	// Example: Writing to Parquet using xitongsys/parquet-go
-	parquetSimpleFilePath := "tmp/users_simple.parquet"
-	if err := writeUsersToParquetSimple(allUsers, parquetSimpleFilePath); err != nil {
-		log.Fatalf("Failed to write users to Parquet (simple): %v", err)
+	if err := writeUsers(allUsers, opts); err != nil {
+		log.Fatalf("Failed to write users: %v", err)
 	}
-	log.Printf("Successfully wrote users to %s\n", parquetSimpleFilePath)
 }
 
-// fetchAllUsers handles the pagination logic to retrieve all users.
-func fetchAllUsers(ctx context.Context) ([]User, error) {
-	var allUsers []User
-	skip := 0
-	limit := defaultPageLimit
-
-	for {
-		// Check for overall job cancellation before fetching a page
-		select {
-		case <-ctx.Done():
-			return nil, fmt.Errorf("job cancelled or timed out: %w", ctx.Err())
-		default:
-		}
-
-		log.Printf("Fetching page: skip=%d, limit=%d\n", skip, limit)
-		pageUsers, err := fetchPageWithRetryableClient(ctx, baseURL, skip, limit)
+// fetchAllUsers handles the pagination logic to retrieve all users, planning concurrent
+// paging when opts.CountURL is set. If stats is non-nil the retries made by client are
+// reported once fetching ends.
+func fetchAllUsers(ctx context.Context, client *retryablehttp.Client, opts Options, stats *ingest.RetryStats) ([]User, error) {
+	users, err := opts.usersClient(client).FetchAll(ctx)
+	if stats != nil {
+		outcome := "completed"
 		if err != nil {
-			return nil, fmt.Errorf("error fetching page at skip %d: %w", skip, err)
+			outcome = "stopped"
 		}
-
-		if len(pageUsers) == 0 {
-			log.Println("Received empty page, assuming end of data.")
-			break // No more users
-		}
-
-		allUsers = append(allUsers, pageUsers...)
-
-		if len(pageUsers) < limit {
-			log.Printf("Received %d users, which is less than limit %d. Assuming end of data.", len(pageUsers), limit)
-			break // This was the last page
-		}
-
-		skip += limit // Move to the next page
+		log.Printf("Fetch %s with %d retries across %d requests (status codes: %v)",
+			outcome, stats.Retries(), stats.Requests(), stats.StatusCodes())
 	}
-	return allUsers, nil
+	return users, err
 }
 
-// fetchPageWithRetryableClient attempts to fetch a single page of users
-// using the configured retryablehttp.Client.
-func fetchPageWithRetryableClient(ctx context.Context, targetURL string, skip int, limit int) ([]User, error) {
-	// Construct URL with query parameters
-	parsedURL, err := url.Parse(targetURL)
-	if err != nil {
-		return nil, fmt.Errorf("invalid base URL '%s': %w", targetURL, err)
-	}
-	queryParams := parsedURL.Query()
-	queryParams.Set("skip", strconv.Itoa(skip))
-	queryParams.Set("limit", strconv.Itoa(limit))
-	parsedURL.RawQuery = queryParams.Encode()
-	fullURL := parsedURL.String()
-
-	// The context passed to NewRequestWithContext governs the entire Do operation,
-	// including all retries and backoff periods.
-	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", fullURL, nil)
-	if err != nil {
-		// This error is critical (e.g., bad method for NewRequestWithContext)
-		return nil, fmt.Errorf("failed to create HTTP request for %s: %w", fullURL, err)
-	}
-	req.Header.Set("Accept", "application/json")
+// Options holds the ingest command-line settings.
+type Options struct {
+	BaseURL     string        // Users endpoint paginated with skip/limit
+	AuthToken   string        // Optional bearer token sent with every request; never logged
+	PageTimeout time.Duration // Deadline for each page request including retries, zero for none
+	MaxPages    int           // Stop after this many pages, zero for unlimited
+	MaxRecords  int           // Stop after this many records, zero for unlimited
+	EndStatuses []int         // Response statuses treated as the end of the data
+	SavePartial bool          // Write users fetched before an interrupt instead of failing
+	CountURL    string        // Optional endpoint returning {"count": N} to plan concurrent paging
+	Workers     int           // Number of pages fetched concurrently when the count is known
+	Verbose     bool          // Log every HTTP attempt and response status
+
+	ingest.WriteOptions // Output path, format, compression and partitioning
+}
 
-	log.Printf("Sending GET request (via retryable client) to %s\n", fullURL)
-	resp, err := sharedRetryableClient.Do(req)
-	if err != nil {
-		// This error means all retries by sharedRetryableClient have been exhausted,
-		// or a non-retryable error occurred as per its CheckRetry policy,
-		// or the parent context (ctx) was cancelled.
-		return nil, fmt.Errorf("failed to fetch page from %s after retries: %w", fullURL, err)
-	}
-	defer resp.Body.Close()
-
-	// Read body (after successful response from retryablehttp client)
-	body, readErr := io.ReadAll(resp.Body)
-	if readErr != nil {
-		// This error occurs after a response was successfully received (headers).
-		// retryablehttp won't retry this part.
-		return nil, fmt.Errorf("failed to read response body from %s (status %d): %w", fullURL, resp.StatusCode, readErr)
-	}
+// fetchOptions returns the page request settings implied by the options.
+func (o Options) fetchOptions() ingest.FetchOptions {
+	fetchOpts := ingest.FetchOptions{
+		PageTimeout: o.PageTimeout,
+		MaxPages:    o.MaxPages,
+		MaxRecords:  o.MaxRecords,
 
-	// Check status code. retryablehttp.Do returns an error for non-2xx responses
-	// that are not retried further. However, it's good practice to verify.
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("server returned non-OK status %d for %s after retries. Body: %s", resp.StatusCode, fullURL, string(body))
+		EndOfDataStatuses: o.EndStatuses,
 	}
-
-	var users []User
-	if err := json.Unmarshal(body, &users); err != nil {
-		// JSON unmarshalling error after a 200 OK.
-		// This is treated as a terminal error for this page fetch.
-		return nil, fmt.Errorf("failed to unmarshal JSON response from %s (status %d). Body: %s. Error: %w",
-			fullURL, resp.StatusCode, string(body), err)
+	if o.AuthToken != "" {
+		fetchOpts.Headers = map[string]string{"Authorization": "Bearer " + o.AuthToken}
 	}
-
-	return users, nil
+	return fetchOpts
 }
 
-// writeUsersToJSON writes a slice of User structs to a JSON file.
-func writeUsersToJSON(users []User, filePath string) error {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create JSON file '%s': %w", filePath, err)
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ") // Optional: for pretty printing
-	if err := encoder.Encode(users); err != nil {
-		return fmt.Errorf("failed to encode users to JSON file '%s': %w", filePath, err)
+// usersClient returns a client for the users endpoint configured by o.
+func (o Options) usersClient(client *retryablehttp.Client) *ingest.ResourceClient[User] {
+	return &ingest.ResourceClient[User]{
+		Client:   client,
+		BaseURL:  o.BaseURL,
+		PageSize: defaultPageLimit,
+		Options:  o.fetchOptions(),
+		CountURL: o.CountURL,
+		Workers:  o.Workers,
 	}
-	log.Printf("Successfully wrote %d users to JSON file: %s\n", len(users), filePath)
-	return nil
 }
 
-// writeUsersToParquetSimple writes a slice of User structs to a Parquet file
-// using the xitongsys/parquet-go library.
-func writeUsersToParquetSimple(users []User, filePath string) error {
-	fw, err := local.NewLocalFileWriter(filePath)
+// parseFlags parses the ingest command-line arguments into Options, rejecting
+// unknown formats and codecs so that no network work is done with a bad configuration.
+func parseFlags(args []string) (Options, error) {
+	fs := flag.NewFlagSet("ingest", flag.ContinueOnError)
+	baseURL := fs.String("base-url", defaultBaseURL, "users endpoint paginated with skip and limit")
+	authToken := fs.String("auth-token", "", "bearer token sent in the Authorization header of every request")
+	maxPages := fs.Int("max-pages", 0, "stop after fetching this many pages, 0 for unlimited")
+	maxRecords := fs.Int("max-records", 0, "stop after fetching this many users, 0 for unlimited")
+	endStatuses := fs.String("end-of-data-status", "404", "comma-separated response statuses that mark the end of the data, empty for none")
+	pageTimeout := fs.Duration("page-timeout", defaultPageTimeout, "deadline for each page request including retries, 0 for none")
+	out := fs.String("out", "", "output file path (default tmp/users.<format>), or base directory with -partition-by-date (default tmp)")
+	format := fs.String("format", "json", "output format: json, jsonl or parquet")
+	compression := fs.String("compression", "snappy", "parquet compression codec, e.g. snappy, gzip, zstd or uncompressed")
+	savePartial := fs.Bool("save-partial", false, "on SIGINT/SIGTERM, write the users fetched so far instead of exiting")
+	countURL := fs.String("count-url", "", "optional endpoint returning the total user count, e.g. "+defaultBaseURL+"count")
+	workers := fs.Int("workers", ingest.DefaultWorkers, "number of pages fetched concurrently when -count-url is set")
+	verbose := fs.Bool("verbose", false, "log every HTTP request attempt and response status")
+	partitionByDate := fs.Bool("partition-by-date", false, "write parquet output to <out>/dt=YYYY-MM-DD/part.parquet by ingest date")
+	if err := fs.Parse(args); err != nil {
+		return Options{}, err
+	}
+
+	if !slices.Contains(ingest.Formats, *format) {
+		return Options{}, fmt.Errorf("unknown output format %q (expected json, jsonl or parquet)", *format)
+	}
+
+	codec, err := parquet.CompressionCodecFromString(strings.ToUpper(*compression))
 	if err != nil {
-		return fmt.Errorf("failed to create local file writer for parquet '%s': %w", filePath, err)
+		return Options{}, fmt.Errorf("unknown compression codec %q: %w", *compression, err)
 	}
-	defer fw.Close()
 
-	// new(User) is used for schema inference.
-	// The third argument is the concurrency for writing, 1 is fine for this case.
-	pw, err := writer.NewParquetWriter(fw, new(User), 1)
-	if err != nil {
-		return fmt.Errorf("failed to create parquet writer: %w", err)
+	path := *out
+	if *partitionByDate {
+		formatSet := false
+		fs.Visit(func(f *flag.Flag) { formatSet = formatSet || f.Name == "format" })
+		if formatSet && *format != "parquet" {
+			return Options{}, fmt.Errorf("-partition-by-date requires -format parquet, got %q", *format)
+		}
+		*format = "parquet"
+		if path == "" {
+			path = "tmp"
+		}
+	} else if path == "" {
+		path = "tmp/users." + *format
 	}
 
-	// You can customize writer properties if needed, e.g., compression
-	pw.CompressionType = parquet.CompressionCodec_SNAPPY // Example
+	if *maxPages < 0 || *maxRecords < 0 {
+		return Options{}, fmt.Errorf("caps must not be negative: -max-pages=%d, -max-records=%d", *maxPages, *maxRecords)
+	}
 
-	for _, user := range users {
-		if err := pw.Write(user); err != nil {
-			// Attempt to stop writer to clean up, but prioritize the write error
-			_ = pw.WriteStop() // Best effort to close
-			return fmt.Errorf("failed to write user record (ID: %d) to parquet: %w", user.ID, err)
+	var statuses []int
+	for _, field := range strings.Split(*endStatuses, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
 		}
-	}
+		status, err := strconv.Atoi(field)
+		if err != nil || status < 100 || status > 599 {
+			return Options{}, fmt.Errorf("invalid end-of-data status %q", field)
+		}
+		statuses = append(statuses, status)
+	}
+
+	if _, err := url.ParseRequestURI(*baseURL); err != nil {
+		return Options{}, fmt.Errorf("invalid base URL %q: %w", *baseURL, err)
+	}
+
+	return Options{
+		BaseURL:     *baseURL,
+		AuthToken:   *authToken,
+		PageTimeout: *pageTimeout,
+		MaxPages:    *maxPages,
+		MaxRecords:  *maxRecords,
+		EndStatuses: statuses,
+		SavePartial: *savePartial,
+		CountURL:    *countURL,
+		Workers:     *workers,
+		Verbose:     *verbose,
+
+		WriteOptions: ingest.WriteOptions{
+			Path:            path,
+			Format:          *format,
+			Compression:     codec,
+			PartitionByDate: *partitionByDate,
+		},
+	}, nil
+}
 
-	if err := pw.WriteStop(); err != nil {
-		return fmt.Errorf("failed to stop parquet writer: %w", err)
+// writeUsers writes users to opts.Path using the datarizer writer for opts.Format.
+func writeUsers(users []User, opts Options) error {
+	if err := ingest.WriteRecords(users, opts.WriteOptions, time.Now()); err != nil {
+		return fmt.Errorf("failed to write users as %s to '%s': %w", opts.Format, opts.Path, err)
 	}
-	log.Printf("Successfully wrote %d users to Parquet file (simple): %s\n", len(users), filePath)
+
+	log.Printf("Successfully wrote %d users to %s file: %s\n", len(users), opts.Format, opts.Path)
 	return nil
 }
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kagenihisomi/datarizer/datarizer"
+	"github.com/kagenihisomi/gogogo/internal/ingest"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// TestParseFlagsAndWriteUsers checks flag parsing and writing a small dataset in each format.
+func TestParseFlagsAndWriteUsers(t *testing.T) {
+	users := []User{
+		{ID: 1, Name: "Alice", Email: "alice@example.com", Age: 30},
+		{ID: 2, Name: "Bob", Email: "bob@example.com", Age: 25},
+	}
+	dir := t.TempDir()
+
+	for _, format := range []string{"json", "jsonl", "parquet"} {
+		path := filepath.Join(dir, "out", "users."+format)
+		opts, err := parseFlags([]string{"-format", format, "-out", path, "-compression", "gzip"})
+		if err != nil {
+			t.Fatalf("parseFlags(%s) returned error: %v", format, err)
+		}
+		if err := writeUsers(users, opts); err != nil {
+			t.Fatalf("writeUsers(%s) returned error: %v", format, err)
+		}
+
+		var read []User
+		switch format {
+		case "json":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", path, err)
+			}
+			if err := json.Unmarshal(data, &read); err != nil {
+				t.Fatalf("Failed to decode %s: %v", path, err)
+			}
+			if !strings.HasPrefix(string(data), "[\n  {\n    ") {
+				t.Errorf("Expected indented JSON output, got %q", data)
+			}
+		case "jsonl":
+			df, err := datarizer.ReadFromJSONL[User](path)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", path, err)
+			}
+			read = df.Records
+		case "parquet":
+			df, err := datarizer.ReadFromLocalParquet[User](path)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", path, err)
+			}
+			read = df.Records
+		}
+
+		if len(read) != len(users) {
+			t.Fatalf("%s: record count mismatch: got %d want %d", format, len(read), len(users))
+		}
+		for i, user := range users {
+			if read[i] != user {
+				t.Errorf("%s: record %d mismatch: got %+v want %+v", format, i, read[i], user)
+			}
+		}
+	}
+
+	// Defaults
+	opts, err := parseFlags(nil)
+	if err != nil {
+		t.Fatalf("parseFlags with defaults returned error: %v", err)
+	}
+	if opts.Format != "json" || opts.Path != "tmp/users.json" || opts.Compression != parquet.CompressionCodec_SNAPPY || opts.PageTimeout != 0 {
+		t.Errorf("Unexpected default options: %+v", opts)
+	}
+
+	// Unknown formats and codecs are rejected up front
+	if _, err := parseFlags([]string{"-format", "xml"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if _, err := parseFlags([]string{"-compression", "bogus"}); err == nil {
+		t.Error("Expected an error for an unknown compression codec")
+	}
+}
+
+// TestFetchAllUsersAuth checks that -base-url and -auth-token are used for every request
+// and that the token is not logged.
+func TestFetchAllUsersAuth(t *testing.T) {
+	const token = "s3cret-token"
+	users := []User{
+		{ID: 1, Name: "Alice"},
+		{ID: 2, Name: "Bob"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("skip") == "0" {
+			json.NewEncoder(w).Encode(users)
+			return
+		}
+		json.NewEncoder(w).Encode([]User{})
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	opts, err := parseFlags([]string{"-base-url", server.URL, "-auth-token", token})
+	if err != nil {
+		t.Fatalf("parseFlags returned error: %v", err)
+	}
+	client := ingest.NewIngestClient(ingest.IngestOptions{
+		RetryMax:       1,
+		RetryWaitMin:   1 * time.Millisecond,
+		RetryWaitMax:   5 * time.Millisecond,
+		RequestTimeout: 1 * time.Second,
+		Verbose:        true,
+	})
+
+	fetched, err := fetchAllUsers(context.Background(), client, opts, &ingest.RetryStats{})
+	if err != nil {
+		t.Fatalf("fetchAllUsers returned error: %v", err)
+	}
+	if len(fetched) != len(users) {
+		t.Errorf("Fetched user count mismatch: expected=%d, got=%d", len(users), len(fetched))
+	}
+	if strings.Contains(logs.String(), token) {
+		t.Errorf("Auth token was written to the log")
+	}
+
+	// Without the token the server rejects the request
+	opts.AuthToken = ""
+	if _, err := fetchAllUsers(context.Background(), client, opts, nil); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected a 401 error without the token, got %v", err)
+	}
+
+	if _, err := parseFlags([]string{"-base-url", "not a url"}); err == nil {
+		t.Error("Expected an error for an invalid base URL")
+	}
+}
+
+// TestParseFlagsFetchOptions checks that the paging flags end up in the fetch options and
+// the users client.
+func TestParseFlagsFetchOptions(t *testing.T) {
+	opts, err := parseFlags([]string{
+		"-base-url", "http://example.com/users/",
+		"-auth-token", "token",
+		"-max-pages", "4",
+		"-max-records", "3",
+		"-end-of-data-status", "404, 410",
+		"-page-timeout", "30s",
+		"-count-url", "http://example.com/users/count",
+		"-workers", "8",
+	})
+	if err != nil {
+		t.Fatalf("parseFlags returned error: %v", err)
+	}
+
+	fetchOpts := opts.fetchOptions()
+	if fetchOpts.MaxPages != 4 || fetchOpts.MaxRecords != 3 || fetchOpts.PageTimeout != 30*time.Second {
+		t.Errorf("Unexpected caps or timeout: %+v", fetchOpts)
+	}
+	if !slices.Equal(fetchOpts.EndOfDataStatuses, []int{404, 410}) {
+		t.Errorf("EndOfDataStatuses mismatch: expected=[404 410], got=%v", fetchOpts.EndOfDataStatuses)
+	}
+	if got := fetchOpts.Headers["Authorization"]; got != "Bearer token" {
+		t.Errorf("Authorization header mismatch: got %q", got)
+	}
+
+	client := opts.usersClient(nil)
+	if client.BaseURL != "http://example.com/users/" || client.PageSize != defaultPageLimit ||
+		client.CountURL != "http://example.com/users/count" || client.Workers != 8 {
+		t.Errorf("Unexpected users client: %+v", client)
+	}
+
+	// Defaults end paging on 404 and leave the caps off
+	opts, err = parseFlags(nil)
+	if err != nil {
+		t.Fatalf("parseFlags with defaults returned error: %v", err)
+	}
+	fetchOpts = opts.fetchOptions()
+	if fetchOpts.MaxPages != 0 || fetchOpts.MaxRecords != 0 || fetchOpts.Headers != nil || !slices.Equal(fetchOpts.EndOfDataStatuses, []int{404}) {
+		t.Errorf("Unexpected default fetch options: %+v", fetchOpts)
+	}
+
+	opts, err = parseFlags([]string{"-end-of-data-status", ""})
+	if err != nil {
+		t.Fatalf("parseFlags returned error: %v", err)
+	}
+	if len(opts.fetchOptions().EndOfDataStatuses) != 0 {
+		t.Errorf("Expected no end-of-data statuses, got %v", opts.fetchOptions().EndOfDataStatuses)
+	}
+
+	for _, args := range [][]string{
+		{"-max-pages", "-1"},
+		{"-end-of-data-status", "404,abc"},
+		{"-end-of-data-status", "99"},
+	} {
+		if _, err := parseFlags(args); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
+
+// TestParseFlagsPartitionByDate tests that -partition-by-date writes users below -out by ingest date.
+func TestParseFlagsPartitionByDate(t *testing.T) {
+	dir := t.TempDir()
+	opts, err := parseFlags([]string{"-partition-by-date", "-out", dir})
+	if err != nil {
+		t.Fatalf("parseFlags returned error: %v", err)
+	}
+	if opts.Format != "parquet" || !opts.PartitionByDate || opts.Path != dir {
+		t.Fatalf("Expected partitioned parquet output in %s, got %+v", dir, opts.WriteOptions)
+	}
+	if err := writeUsers([]User{{ID: 1, Name: "Alice"}}, opts); err != nil {
+		t.Fatalf("writeUsers returned error: %v", err)
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "dt=*", "part.parquet"))
+	if err != nil || len(matches) != 1 {
+		t.Errorf("Expected one partition file, got %v (err %v)", matches, err)
+	}
+
+	opts, err = parseFlags([]string{"-partition-by-date"})
+	if err != nil {
+		t.Fatalf("parseFlags returned error: %v", err)
+	}
+	if opts.Path != "tmp" {
+		t.Errorf("Expected the default base directory tmp, got %s", opts.Path)
+	}
+
+	if _, err := parseFlags([]string{"-partition-by-date", "-format", "jsonl"}); err == nil {
+		t.Error("Expected an error combining -partition-by-date with -format jsonl")
+	}
+}
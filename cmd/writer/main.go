@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 
@@ -29,25 +28,14 @@ func main() {
 		}
 	]`
 
-	// Unmarshal the JSON array into a slice of json.RawMessage
-	var rawRecords []json.RawMessage
-	if err := json.Unmarshal([]byte(jsonData), &rawRecords); err != nil {
-		fmt.Printf("failed to unmarshal JSON array: %v\n", err)
-		os.Exit(1)
-	}
-
 	// Create a parser for the Student type
 	parser := datarizer.BaseSchemaParser[datarizer.Student]{}
 
-	// Parse each raw record using ParseFromJson
-	var students []datarizer.Student
-	for _, raw := range rawRecords {
-		student, err := parser.ParseFromJson(raw, "myjson")
-		if err != nil {
-			fmt.Printf("failed to parse record: %v\n", err)
-			os.Exit(1)
-		}
-		students = append(students, student)
+	// Parse the whole JSON array, enriching each record with RecordInfo
+	students, err := parser.ParseFromJsonArray([]byte(jsonData), "myjson")
+	if err != nil {
+		fmt.Printf("failed to parse records: %v\n", err)
+		os.Exit(1)
 	}
 
 	// Now students slice contains all enriched Student records
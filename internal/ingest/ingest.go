@@ -0,0 +1,632 @@
+// Package ingest fetches JSON records from paginated HTTP APIs with a retrying client and
+// writes them out with the datarizer writers. It is shared by the ingest and gogogo commands.
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const (
+	DefaultPageSize = 50 // Records requested per page when none is configured
+	DefaultWorkers  = 4  // Pages fetched concurrently when the total count is known
+	maxRetries      = 5
+	initialBackoff  = 1 * time.Second
+	maxBackoff      = 30 * time.Second
+	requestTimeout  = 15 * time.Second // Timeout for each individual HTTP request attempt
+)
+
+// IngestOptions configures the retrying HTTP client used by the ingest job.
+type IngestOptions struct {
+	RetryMax       int           // Maximum number of retries per request
+	RetryWaitMin   time.Duration // Minimum backoff between retries
+	RetryWaitMax   time.Duration // Maximum backoff between retries
+	RequestTimeout time.Duration // Timeout for each individual HTTP request attempt
+	Verbose        bool          // Log every request attempt and response status
+	Stats          *RetryStats   // Optional counters updated on every attempt
+}
+
+// RetryStats counts the requests, retries and response status codes seen by an
+// ingest client. It is safe for concurrent use.
+type RetryStats struct {
+	mu          sync.Mutex
+	requests    int
+	retries     int
+	statusCodes map[int]int
+}
+
+// recordAttempt records an attempt, where attempt is 0 for the initial request.
+func (s *RetryStats) recordAttempt(attempt int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if attempt == 0 {
+		s.requests++
+	} else {
+		s.retries++
+	}
+}
+
+// recordStatus records the status code of a response.
+func (s *RetryStats) recordStatus(code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.statusCodes == nil {
+		s.statusCodes = make(map[int]int)
+	}
+	s.statusCodes[code]++
+}
+
+// Requests returns the number of requests made, not counting retries.
+func (s *RetryStats) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// Retries returns the number of retried attempts.
+func (s *RetryStats) Retries() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.retries
+}
+
+// StatusCodes returns a copy of the number of responses seen per status code.
+func (s *RetryStats) StatusCodes() map[int]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	codes := make(map[int]int, len(s.statusCodes))
+	for code, n := range s.statusCodes {
+		codes[code] = n
+	}
+	return codes
+}
+
+// DefaultIngestOptions returns the options used by the ingest job in production.
+func DefaultIngestOptions() IngestOptions {
+	return IngestOptions{
+		RetryMax:       maxRetries,
+		RetryWaitMin:   initialBackoff,
+		RetryWaitMax:   maxBackoff,
+		RequestTimeout: requestTimeout,
+	}
+}
+
+// NewIngestClient creates a retryable HTTP client configured from opts.
+// Callers should share the returned client for connection reuse.
+func NewIngestClient(opts IngestOptions) *retryablehttp.Client {
+	client := retryablehttp.NewClient()
+	client.RetryMax = opts.RetryMax
+	client.RetryWaitMin = opts.RetryWaitMin
+	client.RetryWaitMax = opts.RetryWaitMax
+	// The client.HTTPClient is a standard *http.Client.
+	// We set its timeout for individual attempts made by the retryablehttp client.
+	client.HTTPClient.Timeout = opts.RequestTimeout
+
+	// Configure the logger for retryablehttp.
+	// Set to nil or a logger that writes to io.Discard to suppress verbose logging from the library.
+	// If you need to debug retry attempts, you can set it to log.Default() or a custom logger.
+	client.Logger = nil // Suppress verbose library logging by default
+
+	// The hooks still run with a nil logger, so use them to count attempts and
+	// optionally log each one instead of enabling the library's own logging.
+	if opts.Stats != nil || opts.Verbose {
+		client.RequestLogHook = func(_ retryablehttp.Logger, req *http.Request, attempt int) {
+			if opts.Stats != nil {
+				opts.Stats.recordAttempt(attempt)
+			}
+			if opts.Verbose {
+				log.Printf("Attempt %d: %s %s\n", attempt+1, req.Method, req.URL)
+			}
+		}
+		client.ResponseLogHook = func(_ retryablehttp.Logger, resp *http.Response) {
+			if opts.Stats != nil {
+				opts.Stats.recordStatus(resp.StatusCode)
+			}
+			if opts.Verbose {
+				log.Printf("Response: %s %s -> %d\n", resp.Request.Method, resp.Request.URL, resp.StatusCode)
+			}
+		}
+	}
+
+	// Return the last response once retries are exhausted instead of a generic
+	// "giving up" error, so callers see its status code as an HTTPStatusError.
+	client.ErrorHandler = retryablehttp.PassthroughErrorHandler
+
+	// The DefaultRetryPolicy is generally sufficient and covers common retry scenarios
+	// like network errors, 429s, and 5xx server errors.
+	// client.CheckRetry = retryablehttp.DefaultRetryPolicy (this is the default)
+
+	return client
+}
+
+// FetchOptions configures the individual page requests made by the fetch functions.
+type FetchOptions struct {
+	Headers map[string]string // Extra request headers, e.g. Authorization; never logged
+	// PageTimeout bounds each page request including its retries, so one stuck page fails
+	// fast instead of using up the job's budget. Zero means no per-page deadline.
+	PageTimeout time.Duration
+	// MaxPages and MaxRecords stop skip/limit paging early once either cap is reached,
+	// truncating the last page to MaxRecords. Zero means unlimited.
+	MaxPages   int
+	MaxRecords int
+	// EndOfDataStatuses lists response status codes that mark the end of skip/limit paging,
+	// e.g. 404 from APIs that reject a skip past the last record. The records collected so
+	// far are returned without error. Other non-OK statuses still fail.
+	EndOfDataStatuses []int
+}
+
+// capReached reports whether fetching can stop after pages pages holding records records.
+func (o FetchOptions) capReached(pages, records int) bool {
+	return (o.MaxPages > 0 && pages >= o.MaxPages) || (o.MaxRecords > 0 && records >= o.MaxRecords)
+}
+
+// truncateRecords drops any records beyond maxRecords, where zero means unlimited.
+func truncateRecords[T any](records []T, maxRecords int) []T {
+	if maxRecords > 0 && len(records) > maxRecords {
+		return records[:maxRecords]
+	}
+	return records
+}
+
+// ResourceClient fetches records of type T from one skip/limit paginated endpoint, e.g.
+// ResourceClient[User] for the users API. The zero value of optional fields is usable.
+type ResourceClient[T any] struct {
+	Client   *retryablehttp.Client // Retrying HTTP client, e.g. from NewIngestClient
+	BaseURL  string                // Endpoint accepting skip and limit query parameters
+	PageSize int                   // Records requested per page; zero uses DefaultPageSize
+	Options  FetchOptions          // Headers, timeouts and caps applied to every page request
+	// CountURL optionally returns the total record count, letting FetchAll fetch Workers
+	// pages concurrently. See FetchAllPagesWithCount.
+	CountURL string
+	Workers  int
+}
+
+// pageSize returns the configured page size or the default.
+func (c *ResourceClient[T]) pageSize() int {
+	if c.PageSize > 0 {
+		return c.PageSize
+	}
+	return DefaultPageSize
+}
+
+// FetchPage fetches the limit records starting at skip.
+func (c *ResourceClient[T]) FetchPage(ctx context.Context, skip, limit int) ([]T, error) {
+	return fetchPageWithRetryableClient[T](ctx, c.Client, c.BaseURL, c.Options, skip, limit)
+}
+
+// FetchAll fetches every record of the resource, paging sequentially unless CountURL is set.
+// If ctx is cancelled during sequential paging, the records fetched so far are returned
+// along with the error.
+func (c *ResourceClient[T]) FetchAll(ctx context.Context) ([]T, error) {
+	if c.CountURL != "" {
+		workers := c.Workers
+		if workers <= 0 {
+			workers = DefaultWorkers
+		}
+		return FetchAllPagesWithCount[T](ctx, c.Client, c.BaseURL, c.Options, c.CountURL, c.pageSize(), workers)
+	}
+	return FetchAllPages[T](ctx, c.Client, c.BaseURL, c.Options, c.pageSize())
+}
+
+// FetchAllPages retrieves every record from a skip/limit paginated endpoint,
+// requesting pages of pageSize until a short or empty page is returned. fetchOpts
+// sets the headers and deadline of each page request.
+// If ctx is cancelled, the records fetched so far are returned along with the error.
+func FetchAllPages[T any](ctx context.Context, client *retryablehttp.Client, baseURL string, fetchOpts FetchOptions, pageSize int) ([]T, error) {
+	var allRecords []T
+	skip := 0
+	limit := pageSize
+	pages := 0
+
+	for {
+		// Check for overall job cancellation before fetching a page
+		select {
+		case <-ctx.Done():
+			return allRecords, fmt.Errorf("job cancelled or timed out: %w", ctx.Err())
+		default:
+		}
+
+		log.Printf("Fetching page: skip=%d, limit=%d\n", skip, limit)
+		pageRecords, err := fetchPageWithRetryableClient[T](ctx, client, baseURL, fetchOpts, skip, limit)
+		if err != nil {
+			if ctx.Err() != nil {
+				return allRecords, fmt.Errorf("job cancelled or timed out at skip %d: %w", skip, ctx.Err())
+			}
+			var statusErr *HTTPStatusError
+			if errors.As(err, &statusErr) && slices.Contains(fetchOpts.EndOfDataStatuses, statusErr.StatusCode) {
+				log.Printf("Received status %d at skip %d, assuming end of data.", statusErr.StatusCode, skip)
+				break
+			}
+			return nil, fmt.Errorf("error fetching page at skip %d: %w", skip, err)
+		}
+
+		if len(pageRecords) == 0 {
+			log.Println("Received empty page, assuming end of data.")
+			break // No more records
+		}
+
+		allRecords = append(allRecords, pageRecords...)
+		pages++
+
+		if fetchOpts.capReached(pages, len(allRecords)) {
+			allRecords = truncateRecords(allRecords, fetchOpts.MaxRecords)
+			log.Printf("Reached the cap of %d pages or %d records, stopping with %d records.", fetchOpts.MaxPages, fetchOpts.MaxRecords, len(allRecords))
+			break
+		}
+
+		if len(pageRecords) < limit {
+			log.Printf("Received %d records, which is less than limit %d. Assuming end of data.", len(pageRecords), limit)
+			break // This was the last page
+		}
+
+		skip += limit // Move to the next page
+	}
+	return allRecords, nil
+}
+
+// FetchAllPagesConcurrent fetches totalPages pages of pageSize records using a bounded
+// pool of workers, returning the records ordered by page index. The first fatal error
+// cancels all outstanding requests and is returned. A page answered with one of
+// fetchOpts.EndOfDataStatuses ends the data, as in FetchAllPages. If ctx is cancelled,
+// the records of the pages completed before the first missing one are returned along
+// with the error. When totalPages is unknown (<= 0) it falls back to sequential paging
+// with FetchAllPages.
+func FetchAllPagesConcurrent[T any](ctx context.Context, client *retryablehttp.Client, baseURL string, fetchOpts FetchOptions, totalPages, pageSize, workers int) ([]T, error) {
+	if totalPages <= 0 {
+		return FetchAllPages[T](ctx, client, baseURL, fetchOpts, pageSize)
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	// Only plan the pages needed to reach the caps
+	if fetchOpts.MaxPages > 0 {
+		totalPages = min(totalPages, fetchOpts.MaxPages)
+	}
+	if fetchOpts.MaxRecords > 0 && pageSize > 0 {
+		totalPages = min(totalPages, (fetchOpts.MaxRecords+pageSize-1)/pageSize)
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pages := make([][]T, totalPages)
+	fetched := make([]bool, totalPages)
+	pageIndexes := make(chan int)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pageIndexes {
+				skip := page * pageSize
+				log.Printf("Fetching page %d: skip=%d, limit=%d\n", page, skip, pageSize)
+				records, err := fetchPageWithRetryableClient[T](ctx, client, baseURL, fetchOpts, skip, pageSize)
+				if err != nil {
+					if parent.Err() != nil {
+						continue // Reported below with the records fetched so far
+					}
+					var statusErr *HTTPStatusError
+					if errors.As(err, &statusErr) && slices.Contains(fetchOpts.EndOfDataStatuses, statusErr.StatusCode) {
+						log.Printf("Received status %d at skip %d, assuming end of data.", statusErr.StatusCode, skip)
+						continue
+					}
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("error fetching page at skip %d: %w", skip, err)
+						cancel() // Stop outstanding requests
+					})
+					continue
+				}
+				pages[page] = records
+				fetched[page] = true
+			}
+		}()
+	}
+
+	// Dispatch page indexes until done or cancelled
+dispatch:
+	for page := 0; page < totalPages; page++ {
+		select {
+		case pageIndexes <- page:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(pageIndexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// Keep the pages up to the first one that is missing, either because the job was
+	// cancelled or because the server reported the end of the data
+	var allRecords []T
+	for page := 0; page < totalPages && fetched[page]; page++ {
+		allRecords = append(allRecords, pages[page]...)
+	}
+	allRecords = truncateRecords(allRecords, fetchOpts.MaxRecords)
+	if err := parent.Err(); err != nil {
+		return allRecords, fmt.Errorf("job cancelled or timed out: %w", err)
+	}
+	return allRecords, nil
+}
+
+// FetchAllPagesWithCount asks countURL for the total number of records and uses it to
+// fetch the pages concurrently with FetchAllPagesConcurrent. If the count endpoint
+// returns 404 it falls back to sequential paging.
+func FetchAllPagesWithCount[T any](ctx context.Context, client *retryablehttp.Client, baseURL string, fetchOpts FetchOptions, countURL string, pageSize, workers int) ([]T, error) {
+	count, ok, err := fetchTotalCount(ctx, client, countURL, fetchOpts.Headers)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("Count endpoint %s not found, falling back to sequential paging.", countURL)
+		return FetchAllPages[T](ctx, client, baseURL, fetchOpts, pageSize)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+
+	totalPages := (count + pageSize - 1) / pageSize
+	log.Printf("Server reports %d records, fetching %d pages with %d workers.", count, totalPages, workers)
+	return FetchAllPagesConcurrent[T](ctx, client, baseURL, fetchOpts, totalPages, pageSize, workers)
+}
+
+// fetchTotalCount requests a {"count": N} document from countURL. The boolean result
+// is false when the endpoint does not exist (404).
+func fetchTotalCount(ctx context.Context, client *retryablehttp.Client, countURL string, headers map[string]string) (int, bool, error) {
+	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", countURL, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create HTTP request for %s: %w", countURL, err)
+	}
+	setRequestHeaders(req, headers)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to fetch count from %s: %w", countURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, false, &HTTPStatusError{StatusCode: resp.StatusCode, URL: countURL, Body: string(body)}
+	}
+
+	var body struct {
+		Count *int `json:"count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, false, fmt.Errorf("failed to decode count from %s: %w", countURL, err)
+	}
+	if body.Count == nil || *body.Count < 0 {
+		return 0, false, fmt.Errorf("invalid count response from %s", countURL)
+	}
+
+	return *body.Count, true, nil
+}
+
+// fetchPageWithRetryableClient attempts to fetch a single page of records from targetURL
+// using the given retryablehttp.Client, applying the headers and page timeout in fetchOpts.
+func fetchPageWithRetryableClient[T any](ctx context.Context, client *retryablehttp.Client, targetURL string, fetchOpts FetchOptions, skip int, limit int) ([]T, error) {
+	// Construct URL with query parameters
+	parsedURL, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL '%s': %w", targetURL, err)
+	}
+	queryParams := parsedURL.Query()
+	queryParams.Set("skip", strconv.Itoa(skip))
+	queryParams.Set("limit", strconv.Itoa(limit))
+	parsedURL.RawQuery = queryParams.Encode()
+	fullURL := parsedURL.String()
+
+	body, err := getWithRetryableClient(ctx, client, fullURL, fetchOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []T
+	if err := json.Unmarshal(body, &records); err != nil {
+		// JSON unmarshalling error after a 200 OK.
+		// This is treated as a terminal error for this page fetch.
+		return nil, &DecodeError{URL: fullURL, Err: err}
+	}
+
+	return records, nil
+}
+
+// HTTPStatusError is returned when a request ends with a status other than 200 OK after
+// any retries, so callers can branch on the status code with errors.As.
+type HTTPStatusError struct {
+	StatusCode int
+	URL        string
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("server returned non-OK status %d for %s. Body: %s", e.StatusCode, e.URL, e.Body)
+}
+
+// DecodeError is returned when a 200 OK response body is not the expected JSON.
+type DecodeError struct {
+	URL string
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to unmarshal JSON response from %s: %v", e.URL, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// getWithRetryableClient performs a GET request for fullURL using the retryablehttp.Client
+// and returns the body of a 200 OK response. fetchOpts.PageTimeout bounds the request
+// including all retries and reading the body, without cancelling ctx itself.
+func getWithRetryableClient(ctx context.Context, client *retryablehttp.Client, fullURL string, fetchOpts FetchOptions) ([]byte, error) {
+	if fetchOpts.PageTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, fetchOpts.PageTimeout)
+		defer cancel()
+	}
+
+	// The context passed to NewRequestWithContext governs the entire Do operation,
+	// including all retries and backoff periods.
+	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		// This error is critical (e.g., bad method for NewRequestWithContext)
+		return nil, fmt.Errorf("failed to create HTTP request for %s: %w", fullURL, err)
+	}
+	setRequestHeaders(req, fetchOpts.Headers)
+
+	log.Printf("Sending GET request (via retryable client) to %s\n", fullURL)
+	resp, err := client.Do(req)
+	if err != nil {
+		// This error means all retries by the client have been exhausted,
+		// or a non-retryable error occurred as per its CheckRetry policy,
+		// or the parent context (ctx) was cancelled.
+		return nil, fmt.Errorf("failed to fetch page from %s after retries: %w", fullURL, err)
+	}
+	defer resp.Body.Close()
+
+	// Read body (after successful response from retryablehttp client)
+	body, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		// This error occurs after a response was successfully received (headers).
+		// retryablehttp won't retry this part.
+		return nil, fmt.Errorf("failed to read response body from %s (status %d): %w", fullURL, resp.StatusCode, readErr)
+	}
+
+	// Check status code. With the passthrough error handler set by NewIngestClient,
+	// responses that were still failing after the last retry end up here.
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode, URL: fullURL, Body: string(body)}
+	}
+
+	return body, nil
+}
+
+// setRequestHeaders asks for JSON and adds headers to req. Header values may hold
+// credentials, so they must never be logged.
+func setRequestHeaders(req *retryablehttp.Request, headers map[string]string) {
+	req.Header.Set("Accept", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// FetchAllCursor retrieves every record from a cursor paginated endpoint. Each response
+// must be a JSON object; the records are read from itemsJSONPath and the next cursor
+// from cursorJSONPath (dot-separated paths, e.g. "meta.next_cursor"). The cursor is
+// sent back as the cursorParam query parameter until an empty cursor is returned. A response
+// without itemsJSONPath or repeating an earlier cursor is an error. If ctx is cancelled, the
+// records fetched so far are returned along with the error.
+func FetchAllCursor[T any](ctx context.Context, client *retryablehttp.Client, baseURL string, fetchOpts FetchOptions, cursorParam, cursorJSONPath, itemsJSONPath string) ([]T, error) {
+	var allRecords []T
+	cursor := ""
+	seen := make(map[string]bool)
+
+	for {
+		// Check for overall job cancellation before fetching a page
+		select {
+		case <-ctx.Done():
+			return allRecords, fmt.Errorf("job cancelled or timed out: %w", ctx.Err())
+		default:
+		}
+
+		// Construct URL with the cursor query parameter
+		parsedURL, err := url.Parse(baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base URL '%s': %w", baseURL, err)
+		}
+		if cursor != "" {
+			queryParams := parsedURL.Query()
+			queryParams.Set(cursorParam, cursor)
+			parsedURL.RawQuery = queryParams.Encode()
+		}
+		fullURL := parsedURL.String()
+
+		log.Printf("Fetching page: cursor=%q\n", cursor)
+		body, err := getWithRetryableClient(ctx, client, fullURL, fetchOpts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return allRecords, fmt.Errorf("job cancelled or timed out at cursor %q: %w", cursor, ctx.Err())
+			}
+			return nil, fmt.Errorf("error fetching page at cursor %q: %w", cursor, err)
+		}
+
+		var response map[string]json.RawMessage
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal JSON response from %s. Body: %s. Error: %w",
+				fullURL, string(body), err)
+		}
+
+		items, ok := lookupJSONPath(response, itemsJSONPath)
+		if !ok {
+			return nil, fmt.Errorf("no items at '%s' in response from %s", itemsJSONPath, fullURL)
+		}
+		var pageRecords []T
+		if err := json.Unmarshal(items, &pageRecords); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal items at '%s' from %s: %w", itemsJSONPath, fullURL, err)
+		}
+		allRecords = append(allRecords, pageRecords...)
+
+		// A missing, null or empty cursor marks the last page
+		next := ""
+		if raw, ok := lookupJSONPath(response, cursorJSONPath); ok && string(raw) != "null" {
+			if err := json.Unmarshal(raw, &next); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal cursor at '%s' from %s: %w", cursorJSONPath, fullURL, err)
+			}
+		}
+		if next == "" {
+			log.Println("Received empty cursor, assuming end of data.")
+			break
+		}
+		seen[cursor] = true
+		if seen[next] {
+			return nil, fmt.Errorf("server returned cursor %q again from %s", next, fullURL)
+		}
+		cursor = next
+	}
+	return allRecords, nil
+}
+
+// lookupJSONPath resolves a dot-separated path of object keys within a decoded JSON object.
+func lookupJSONPath(object map[string]json.RawMessage, path string) (json.RawMessage, bool) {
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		value, ok := object[key]
+		if !ok {
+			return nil, false
+		}
+		if i == len(keys)-1 {
+			return value, true
+		}
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(value, &nested); err != nil {
+			return nil, false
+		}
+		object = nested
+	}
+	return nil, false
+}
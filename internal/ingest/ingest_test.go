@@ -0,0 +1,734 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// User is the record type served by the test servers
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Age   int    `json:"age"`
+}
+
+// newTestClient returns a retryable client with fast retries for use against httptest servers.
+func newTestClient() *retryablehttp.Client {
+	return NewIngestClient(IngestOptions{
+		RetryMax:       1,
+		RetryWaitMin:   1 * time.Millisecond,
+		RetryWaitMax:   5 * time.Millisecond,
+		RequestTimeout: 1 * time.Second,
+	})
+}
+
+// newUsersServer starts a test server that serves the given users using skip/limit pagination.
+// It returns the server and a counter of the requests it received.
+func newUsersServer(t *testing.T, users []User) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		page := []User{}
+		if skip < len(users) {
+			end := skip + limit
+			if end > len(users) {
+				end = len(users)
+			}
+			page = users[skip:end]
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(page); err != nil {
+			t.Errorf("Failed to encode page: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+// TestNewIngestClient checks that a client built with RetryMax=1 gives up quickly on 503s.
+func TestNewIngestClient(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := newTestClient()
+	if client.RetryMax != 1 {
+		t.Errorf("RetryMax mismatch: expected 1, got %d", client.RetryMax)
+	}
+	if client.HTTPClient.Timeout != 1*time.Second {
+		t.Errorf("RequestTimeout mismatch: expected 1s, got %v", client.HTTPClient.Timeout)
+	}
+
+	start := time.Now()
+	_, err := FetchAllPages[User](context.Background(), client, server.URL, FetchOptions{}, 10)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("Expected an error from a server returning 503")
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected 2 attempts (1 try + 1 retry), got %d", got)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected client to give up quickly, took %v", elapsed)
+	}
+}
+
+// TestFetchAllPages tests that all pages are collected and pagination stops on a short page.
+func TestFetchAllPages(t *testing.T) {
+	users := []User{
+		{ID: 1, Name: "Alice", Email: "alice@example.com", Age: 30},
+		{ID: 2, Name: "Bob", Email: "bob@example.com", Age: 25},
+		{ID: 3, Name: "Charlie", Email: "charlie@example.com", Age: 35},
+		{ID: 4, Name: "Dave", Email: "dave@example.com", Age: 40},
+		{ID: 5, Name: "Eve", Email: "eve@example.com", Age: 28},
+	}
+	server, requests := newUsersServer(t, users)
+
+	fetched, err := FetchAllPages[User](context.Background(), newTestClient(), server.URL, FetchOptions{}, 2)
+	if err != nil {
+		t.Fatalf("FetchAllPages returned error: %v", err)
+	}
+
+	if len(fetched) != len(users) {
+		t.Fatalf("Record count mismatch: got %d want %d", len(fetched), len(users))
+	}
+	for i, user := range users {
+		if fetched[i] != user {
+			t.Errorf("Record %d mismatch: got %+v want %+v", i, fetched[i], user)
+		}
+	}
+	// Pages of 2, 2 and 1 records; the short third page ends pagination.
+	if got := atomic.LoadInt32(requests); got != 3 {
+		t.Errorf("Expected 3 page requests, got %d", got)
+	}
+}
+
+// TestFetchAllPagesCancel checks that cancelling mid-fetch returns promptly with the records fetched so far.
+func TestFetchAllPagesCancel(t *testing.T) {
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("skip") != "0" {
+			// Block until the client gives up on the request
+			close(blocked)
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]User{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-blocked
+		cancel()
+	}()
+
+	start := time.Now()
+	fetched, err := FetchAllPages[User](ctx, newTestClient(), server.URL, FetchOptions{}, 2)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(fetched) != 2 {
+		t.Errorf("Expected the 2 records fetched before cancellation, got %d", len(fetched))
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected a prompt return after cancellation, took %v", elapsed)
+	}
+}
+
+// TestFetchAllCursor tests cursor pagination until an empty cursor is returned.
+func TestFetchAllCursor(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"items": [{"id": 1, "name": "Alice"}, {"id": 2, "name": "Bob"}], "meta": {"next_cursor": "c1"}}`,
+		"c1": `{"items": [{"id": 3, "name": "Charlie"}], "meta": {"next_cursor": "c2"}}`,
+		"c2": `{"items": [{"id": 4, "name": "Dave"}], "meta": {"next_cursor": ""}}`,
+	}
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		page, ok := pages[r.URL.Query().Get("cursor")]
+		if !ok {
+			http.Error(w, "unknown cursor", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	fetched, err := FetchAllCursor[User](context.Background(), newTestClient(), server.URL, FetchOptions{}, "cursor", "meta.next_cursor", "items")
+	if err != nil {
+		t.Fatalf("FetchAllCursor returned error: %v", err)
+	}
+
+	if len(fetched) != 4 {
+		t.Fatalf("Record count mismatch: got %d want 4", len(fetched))
+	}
+	for i, name := range []string{"Alice", "Bob", "Charlie", "Dave"} {
+		if fetched[i].Name != name || fetched[i].ID != i+1 {
+			t.Errorf("Record %d mismatch: got %+v", i, fetched[i])
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("Expected 3 page requests, got %d", got)
+	}
+
+	// A cancelled context stops pagination before any request is made
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FetchAllCursor[User](ctx, newTestClient(), server.URL, FetchOptions{}, "cursor", "meta.next_cursor", "items"); err == nil {
+		t.Errorf("Expected error for cancelled context")
+	}
+
+	// A typo in the items path is an error rather than an empty dataset
+	if _, err := FetchAllCursor[User](context.Background(), newTestClient(), server.URL, FetchOptions{}, "cursor", "meta.next_cursor", "records"); err == nil || !strings.Contains(err.Error(), "records") {
+		t.Errorf("Expected an error for a missing items path, got %v", err)
+	}
+}
+
+// TestFetchAllCursorRepeatedCursor tests that a server repeating a cursor ends pagination with an error.
+func TestFetchAllCursorRepeatedCursor(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"items": [{"id": 1}], "next": "c1"}`,
+		"c1": `{"items": [{"id": 2}], "next": "c2"}`,
+		"c2": `{"items": [{"id": 3}], "next": "c1"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("cursor")]))
+	}))
+	defer server.Close()
+
+	if _, err := FetchAllCursor[User](context.Background(), newTestClient(), server.URL, FetchOptions{}, "cursor", "next", "items"); err == nil || !strings.Contains(err.Error(), `"c1"`) {
+		t.Errorf("Expected an error for the repeated cursor, got %v", err)
+	}
+}
+
+// TestFetchAllCursorCancel checks that cancelling mid-fetch returns the records fetched so far.
+func TestFetchAllCursorCancel(t *testing.T) {
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") != "" {
+			// Block until the client gives up on the request
+			close(blocked)
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items": [{"id": 1}, {"id": 2}], "next": "c1"}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-blocked
+		cancel()
+	}()
+
+	fetched, err := FetchAllCursor[User](ctx, newTestClient(), server.URL, FetchOptions{}, "cursor", "next", "items")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(fetched) != 2 {
+		t.Errorf("Expected the 2 records fetched before cancellation, got %d", len(fetched))
+	}
+}
+
+// TestFetchAllPagesConcurrent tests that pages are fetched in parallel and returned in page order.
+func TestFetchAllPagesConcurrent(t *testing.T) {
+	users := make([]User, 20)
+	for i := range users {
+		users[i] = User{ID: i + 1, Name: "User" + strconv.Itoa(i+1)}
+	}
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond) // Slow API so requests overlap
+
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := skip + limit
+		if end > len(users) {
+			end = len(users)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(users[skip:end])
+	}))
+	defer server.Close()
+
+	fetched, err := FetchAllPagesConcurrent[User](context.Background(), newTestClient(), server.URL, FetchOptions{}, 5, 4, 4)
+	if err != nil {
+		t.Fatalf("FetchAllPagesConcurrent returned error: %v", err)
+	}
+
+	if len(fetched) != len(users) {
+		t.Fatalf("Record count mismatch: got %d want %d", len(fetched), len(users))
+	}
+	for i, user := range users {
+		if fetched[i].ID != user.ID {
+			t.Errorf("Record %d out of order: got ID %d want %d", i, fetched[i].ID, user.ID)
+		}
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got < 2 {
+		t.Errorf("Expected overlapping requests, max in flight was %d", got)
+	}
+}
+
+// TestFetchAllPagesConcurrentError tests that the first fatal error is returned.
+func TestFetchAllPagesConcurrentError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("skip") == "4" {
+			http.Error(w, "bad page", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id": 1}, {"id": 2}]`))
+	}))
+	defer server.Close()
+
+	_, err := FetchAllPagesConcurrent[User](context.Background(), newTestClient(), server.URL, FetchOptions{}, 4, 2, 2)
+	if err == nil {
+		t.Fatal("Expected error for failing page, got nil")
+	}
+}
+
+// TestFetchAllPagesConcurrentCancel checks that cancelling returns the pages completed so far, in order.
+func TestFetchAllPagesConcurrentCancel(t *testing.T) {
+	blocked := make(chan struct{})
+	var once sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		if skip >= 4 {
+			// Block until the client gives up on the request
+			once.Do(func() { close(blocked) })
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]User{{ID: skip + 1}, {ID: skip + 2}})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-blocked
+		cancel()
+	}()
+
+	fetched, err := FetchAllPagesConcurrent[User](ctx, newTestClient(), server.URL, FetchOptions{}, 4, 2, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(fetched) != 4 {
+		t.Fatalf("Expected the 4 records fetched before cancellation, got %d", len(fetched))
+	}
+	for i, user := range fetched {
+		if user.ID != i+1 {
+			t.Errorf("Record %d out of order: got ID %d want %d", i, user.ID, i+1)
+		}
+	}
+}
+
+// TestFetchAllPagesConcurrentEndOfData checks that an end-of-data status stops at that page.
+func TestFetchAllPagesConcurrentEndOfData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		if skip >= 4 {
+			http.Error(w, "page out of range", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]User{{ID: skip + 1}, {ID: skip + 2}})
+	}))
+	defer server.Close()
+
+	fetched, err := FetchAllPagesConcurrent[User](context.Background(), newTestClient(), server.URL, FetchOptions{EndOfDataStatuses: []int{http.StatusNotFound}}, 4, 2, 2)
+	if err != nil {
+		t.Fatalf("Expected a clean finish on 404, got error: %v", err)
+	}
+	if len(fetched) != 4 {
+		t.Errorf("Expected 4 records before the end of data, got %d", len(fetched))
+	}
+
+	if _, err := FetchAllPagesConcurrent[User](context.Background(), newTestClient(), server.URL, FetchOptions{}, 4, 2, 2); err == nil {
+		t.Error("Expected an error for a 404 with no end-of-data statuses configured")
+	}
+}
+
+// TestFetchAllPagesWithCount checks that the count endpoint drives the page plan and that a 404 falls back to sequential paging.
+func TestFetchAllPagesWithCount(t *testing.T) {
+	users := make([]User, 6)
+	for i := range users {
+		users[i] = User{ID: i + 1, Name: "User" + strconv.Itoa(i+1)}
+	}
+
+	for _, tc := range []struct {
+		name         string
+		hasCount     bool
+		wantRequests int32
+	}{
+		// 6 records in pages of 2 are planned as exactly 3 requests
+		{name: "count", hasCount: true, wantRequests: 3},
+		// Sequential paging needs a fourth, empty page to detect the end
+		{name: "fallback", hasCount: false, wantRequests: 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			usersServer, requests := newUsersServer(t, users)
+			mux := http.NewServeMux()
+			mux.HandleFunc("/users/count", func(w http.ResponseWriter, r *http.Request) {
+				if !tc.hasCount {
+					http.NotFound(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]int{"count": len(users)})
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			fetched, err := FetchAllPagesWithCount[User](context.Background(), newTestClient(), usersServer.URL, FetchOptions{}, server.URL+"/users/count", 2, 2)
+			if err != nil {
+				t.Fatalf("FetchAllPagesWithCount returned error: %v", err)
+			}
+			if len(fetched) != len(users) {
+				t.Fatalf("Record count mismatch: got %d want %d", len(fetched), len(users))
+			}
+			for i, user := range users {
+				if fetched[i].ID != user.ID {
+					t.Errorf("Record %d out of order: got ID %d want %d", i, fetched[i].ID, user.ID)
+				}
+			}
+			if got := atomic.LoadInt32(requests); got != tc.wantRequests {
+				t.Errorf("Expected %d page requests, got %d", tc.wantRequests, got)
+			}
+		})
+	}
+}
+
+// TestRetryStats checks that retries against a flaky server are counted by the client hooks.
+func TestRetryStats(t *testing.T) {
+	users := []User{
+		{ID: 1, Name: "Alice"},
+		{ID: 2, Name: "Bob"},
+		{ID: 3, Name: "Charlie"},
+	}
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail every other attempt so each page needs exactly one retry
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := skip + limit
+		if skip > len(users) {
+			skip = len(users)
+		}
+		if end > len(users) {
+			end = len(users)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(users[skip:end])
+	}))
+	defer server.Close()
+
+	stats := &RetryStats{}
+	client := NewIngestClient(IngestOptions{
+		RetryMax:       1,
+		RetryWaitMin:   1 * time.Millisecond,
+		RetryWaitMax:   5 * time.Millisecond,
+		RequestTimeout: 1 * time.Second,
+		Verbose:        true,
+		Stats:          stats,
+	})
+
+	fetched, err := FetchAllPages[User](context.Background(), client, server.URL, FetchOptions{}, 2)
+	if err != nil {
+		t.Fatalf("FetchAllPages returned error: %v", err)
+	}
+	if len(fetched) != len(users) {
+		t.Errorf("Fetched user count mismatch: expected=%d, got=%d", len(users), len(fetched))
+	}
+
+	// Two pages, each failing once before succeeding
+	if got := stats.Requests(); got != 2 {
+		t.Errorf("Requests mismatch: expected=2, got=%d", got)
+	}
+	if got := stats.Retries(); got != 2 {
+		t.Errorf("Retries mismatch: expected=2, got=%d", got)
+	}
+	codes := stats.StatusCodes()
+	if codes[http.StatusServiceUnavailable] != 2 || codes[http.StatusOK] != 2 {
+		t.Errorf("Unexpected status codes: %v", codes)
+	}
+}
+
+// TestFetchAllPagesPageTimeout checks that a stuck page fails with its own deadline while
+// the job context keeps its budget.
+func TestFetchAllPagesPageTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("skip") != "0" {
+			// Stall until the client gives up on the page
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]User{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := FetchAllPages[User](ctx, newTestClient(), server.URL, FetchOptions{PageTimeout: 50 * time.Millisecond}, 2)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "skip 2") {
+		t.Fatalf("Expected the page at skip 2 to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the stuck page to fail fast, took %v", elapsed)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("Page timeout cancelled the job context: %v", ctx.Err())
+	}
+
+	// The job context is still usable for further requests
+	fetched, err := FetchAllPages[User](ctx, newTestClient(), server.URL, FetchOptions{PageTimeout: time.Second}, 3)
+	if err != nil || len(fetched) != 2 {
+		t.Errorf("Expected 2 users after the timeout, got %d (err=%v)", len(fetched), err)
+	}
+}
+
+// TestFetchAllPagesErrorTypes checks that status and decode failures can be told apart with errors.As.
+func TestFetchAllPagesErrorTypes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/broken":
+			http.Error(w, "database unavailable", http.StatusInternalServerError)
+		case "/malformed":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id": 1, "name": "Alice"`))
+		}
+	}))
+	defer server.Close()
+
+	_, err := FetchAllPages[User](context.Background(), newTestClient(), server.URL+"/broken", FetchOptions{}, 10)
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected an HTTPStatusError for a 500, got %T: %v", err, err)
+	}
+	if statusErr.StatusCode != http.StatusInternalServerError || !strings.Contains(statusErr.Body, "database unavailable") ||
+		!strings.HasPrefix(statusErr.URL, server.URL+"/broken") {
+		t.Errorf("Unexpected HTTPStatusError: %+v", statusErr)
+	}
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		t.Errorf("Did not expect a DecodeError for a 500")
+	}
+
+	_, err = FetchAllPages[User](context.Background(), newTestClient(), server.URL+"/malformed", FetchOptions{}, 10)
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Expected a DecodeError for a malformed body, got %T: %v", err, err)
+	}
+	if !strings.HasPrefix(decodeErr.URL, server.URL+"/malformed") || decodeErr.Err == nil {
+		t.Errorf("Unexpected DecodeError: %+v", decodeErr)
+	}
+	if errors.As(err, &statusErr) {
+		t.Errorf("Did not expect an HTTPStatusError for a malformed body")
+	}
+}
+
+// TestResourceClient tests fetching single pages and whole resources through ResourceClient[User].
+func TestResourceClient(t *testing.T) {
+	users := []User{
+		{ID: 1, Name: "Alice", Email: "alice@example.com", Age: 30},
+		{ID: 2, Name: "Bob", Email: "bob@example.com", Age: 25},
+		{ID: 3, Name: "Charlie", Email: "charlie@example.com", Age: 35},
+		{ID: 4, Name: "Dave", Email: "dave@example.com", Age: 40},
+		{ID: 5, Name: "Eve", Email: "eve@example.com", Age: 28},
+	}
+	var pageRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" {
+			http.Error(w, "missing API key", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/users/count" {
+			json.NewEncoder(w).Encode(map[string]int{"count": len(users)})
+			return
+		}
+		atomic.AddInt32(&pageRequests, 1)
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := min(skip+limit, len(users))
+		json.NewEncoder(w).Encode(users[min(skip, end):end])
+	}))
+	defer server.Close()
+
+	client := &ResourceClient[User]{
+		Client:   newTestClient(),
+		BaseURL:  server.URL + "/users/",
+		PageSize: 2,
+		Options:  FetchOptions{Headers: map[string]string{"X-Api-Key": "key"}},
+	}
+
+	page, err := client.FetchPage(context.Background(), 1, 3)
+	if err != nil {
+		t.Fatalf("FetchPage returned error: %v", err)
+	}
+	if len(page) != 3 || page[0] != users[1] || page[2] != users[3] {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+
+	fetched, err := client.FetchAll(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAll returned error: %v", err)
+	}
+	if len(fetched) != len(users) {
+		t.Fatalf("Record count mismatch: got %d want %d", len(fetched), len(users))
+	}
+	// One page from FetchPage, then pages of 2, 2 and 1 records
+	if got := atomic.LoadInt32(&pageRequests); got != 4 {
+		t.Errorf("Expected 4 page requests, got %d", got)
+	}
+
+	client.CountURL = server.URL + "/users/count"
+	fetched, err = client.FetchAll(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAll with CountURL returned error: %v", err)
+	}
+	for i, user := range users {
+		if i >= len(fetched) || fetched[i] != user {
+			t.Errorf("Record %d mismatch with CountURL: got %+v want %+v", i, fetched, user)
+			break
+		}
+	}
+
+	client.Options.Headers = nil
+	var statusErr *HTTPStatusError
+	if _, err := client.FetchPage(context.Background(), 0, 2); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 HTTPStatusError without the API key, got %v", err)
+	}
+}
+
+// TestFetchAllPagesCaps checks that MaxPages and MaxRecords stop paging early.
+func TestFetchAllPagesCaps(t *testing.T) {
+	users := make([]User, 1000)
+	for i := range users {
+		users[i] = User{ID: i + 1, Name: "User" + strconv.Itoa(i+1)}
+	}
+
+	tests := []struct {
+		name         string
+		opts         FetchOptions
+		wantRecords  int
+		wantRequests int32
+	}{
+		{"max records truncates the last page", FetchOptions{MaxRecords: 5}, 5, 3},
+		{"max pages", FetchOptions{MaxPages: 2}, 4, 2},
+		{"first cap wins", FetchOptions{MaxPages: 4, MaxRecords: 3}, 3, 2},
+		{"unlimited", FetchOptions{}, len(users), 501},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newUsersServer(t, users)
+
+			fetched, err := FetchAllPages[User](context.Background(), newTestClient(), server.URL, tt.opts, 2)
+			if err != nil {
+				t.Fatalf("FetchAllPages returned error: %v", err)
+			}
+			if len(fetched) != tt.wantRecords {
+				t.Errorf("Fetched user count mismatch: expected=%d, got=%d", tt.wantRecords, len(fetched))
+			}
+			if got := atomic.LoadInt32(requests); got != tt.wantRequests {
+				t.Errorf("Request count mismatch: expected=%d, got=%d", tt.wantRequests, got)
+			}
+			if len(fetched) > 0 && fetched[len(fetched)-1].ID != tt.wantRecords {
+				t.Errorf("Expected the first %d users, last was %+v", tt.wantRecords, fetched[len(fetched)-1])
+			}
+		})
+	}
+
+	// Concurrent paging only plans the pages needed for the cap
+	server, requests := newUsersServer(t, users)
+	fetched, err := FetchAllPagesConcurrent[User](context.Background(), newTestClient(), server.URL, FetchOptions{MaxRecords: 5}, 500, 2, 4)
+	if err != nil {
+		t.Fatalf("FetchAllPagesConcurrent returned error: %v", err)
+	}
+	if len(fetched) != 5 || atomic.LoadInt32(requests) != 3 {
+		t.Errorf("Expected 5 users from 3 requests, got %d users from %d requests", len(fetched), atomic.LoadInt32(requests))
+	}
+}
+
+// TestFetchAllPagesEndOfData tests that an end-of-data status past the last page ends
+// pagination cleanly while other error statuses still fail.
+func TestFetchAllPagesEndOfData(t *testing.T) {
+	var users []User
+	for i := 1; i <= 4; i++ {
+		users = append(users, User{ID: i, Name: "User " + strconv.Itoa(i)})
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		if skip >= len(users) {
+			status := http.StatusNotFound
+			if r.URL.Path == "/broken" {
+				status = http.StatusInternalServerError
+			}
+			http.Error(w, "page out of range", status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(users[skip : skip+2])
+	}))
+	defer server.Close()
+
+	endOfData := FetchOptions{EndOfDataStatuses: []int{http.StatusNotFound}}
+	fetched, err := FetchAllPages[User](context.Background(), newTestClient(), server.URL, endOfData, 2)
+	if err != nil {
+		t.Fatalf("Expected a clean finish on 404, got error: %v", err)
+	}
+	if len(fetched) != len(users) {
+		t.Errorf("Expected %d users, got %d", len(users), len(fetched))
+	}
+
+	if _, err := FetchAllPages[User](context.Background(), newTestClient(), server.URL+"/broken", endOfData, 2); err == nil {
+		t.Error("Expected an error for a 500 past the last page")
+	}
+	if _, err := FetchAllPages[User](context.Background(), newTestClient(), server.URL, FetchOptions{}, 2); err == nil {
+		t.Error("Expected an error for a 404 with no end-of-data statuses configured")
+	}
+}
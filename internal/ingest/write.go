@@ -0,0 +1,50 @@
+package ingest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kagenihisomi/datarizer/datarizer"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// Formats lists the output formats accepted by WriteRecords.
+var Formats = []string{"json", "jsonl", "parquet"}
+
+// WriteOptions selects where and how WriteRecords writes records.
+type WriteOptions struct {
+	Path        string                   // Output file path, or base directory with PartitionByDate
+	Format      string                   // One of Formats
+	Compression parquet.CompressionCodec // Parquet compression codec
+
+	// PartitionByDate writes Parquet output to Path/dt=YYYY-MM-DD/part.parquet by ingest date
+	PartitionByDate bool
+}
+
+// WriteRecords writes records in the format and to the path given by opts. With
+// opts.PartitionByDate, opts.Path is the base directory of the date partitions, and records
+// without an ingest timestamp go to the partition for now.
+func WriteRecords[T any](records []T, opts WriteOptions, now time.Time) error {
+	df := datarizer.CreateDataFrame(records)
+
+	switch opts.Format {
+	case "json":
+		return df.WriteToJSON(opts.Path)
+	case "jsonl":
+		return df.WriteToJSONL(opts.Path)
+	case "parquet":
+		config := datarizer.DefaultParquetConfig()
+		config.Compression = opts.Compression
+		if opts.PartitionByDate {
+			return df.WriteToLocalParquetPartitioned(opts.Path, datarizer.PartitionByIngestDate[T](now), config)
+		}
+		if err := os.MkdirAll(filepath.Dir(opts.Path), 0755); err != nil {
+			return err
+		}
+		return df.WriteToLocalParquet(opts.Path, config)
+	default:
+		return fmt.Errorf("unknown output format %q", opts.Format)
+	}
+}
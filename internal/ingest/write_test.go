@@ -0,0 +1,62 @@
+package ingest
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kagenihisomi/datarizer/datarizer"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// TestWriteRecordsPartitionByDate tests that PartitionByDate writes one Parquet file per ingest date.
+func TestWriteRecordsPartitionByDate(t *testing.T) {
+	type IngestedUser struct {
+		ID         int                  `json:"id" parquet:"name=id, type=INT32"`
+		RecordInfo datarizer.RecordInfo `json:"_recordinfo" parquet:"name=_recordinfo"`
+	}
+	day1 := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	day2 := time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC)
+	records := []IngestedUser{
+		{ID: 1, RecordInfo: datarizer.RecordInfo{IngestTimestamp: day1.UnixMilli()}},
+		{ID: 2, RecordInfo: datarizer.RecordInfo{IngestTimestamp: day2.UnixMilli()}},
+		{ID: 3, RecordInfo: datarizer.RecordInfo{IngestTimestamp: day1.Add(time.Hour).UnixMilli()}},
+	}
+
+	dir := t.TempDir()
+	opts := WriteOptions{Path: dir, Format: "parquet", Compression: parquet.CompressionCodec_SNAPPY, PartitionByDate: true}
+	if err := WriteRecords(records, opts, day2); err != nil {
+		t.Fatalf("WriteRecords returned error: %v", err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "dt=*", "part.parquet"))
+	if err != nil {
+		t.Fatalf("Failed to list partitions: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 partition files, got %v", matches)
+	}
+	df, err := datarizer.ReadFromLocalParquet[IngestedUser](filepath.Join(dir, "dt=2024-06-01", "part.parquet"))
+	if err != nil {
+		t.Fatalf("Failed to read partition: %v", err)
+	}
+	if len(df.Records) != 2 || df.Records[0].ID != 1 || df.Records[1].ID != 3 {
+		t.Errorf("Unexpected records in dt=2024-06-01: %+v", df.Records)
+	}
+
+	// Records without an ingest timestamp go to the partition for the current date
+	type PlainUser struct {
+		ID int `json:"id" parquet:"name=id, type=INT32"`
+	}
+	if err := WriteRecords([]PlainUser{{ID: 4}}, opts, day1); err != nil {
+		t.Fatalf("WriteRecords returned error: %v", err)
+	}
+	plain, err := datarizer.ReadFromLocalParquet[PlainUser](filepath.Join(dir, "dt=2024-06-01", "part.parquet"))
+	if err != nil || len(plain.Records) != 1 || plain.Records[0].ID != 4 {
+		t.Errorf("Expected the record to replace dt=2024-06-01, got %+v (err %v)", plain, err)
+	}
+
+	if err := WriteRecords(records, WriteOptions{Path: filepath.Join(dir, "out.xml"), Format: "xml"}, day1); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -3,22 +3,41 @@ package datarizer
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/sha256"
+	"encoding/csv"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	awsClient "github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
 	awsS3 "github.com/aws/aws-sdk-go/service/s3" // Use alias to avoid conflict
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/ocf"
 	// Use alias to avoid conflict
+	"github.com/xitongsys/parquet-go-source/buffer"
 	"github.com/xitongsys/parquet-go-source/local"
 	"github.com/xitongsys/parquet-go-source/s3"
+	"github.com/xitongsys/parquet-go/common"
 	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/schema"
 	"github.com/xitongsys/parquet-go/source"
 	"github.com/xitongsys/parquet-go/writer"
 )
@@ -31,16 +50,16 @@ type Student struct {
 	Weight  float32 `parquet:"name=weight, type=FLOAT"`
 	Sex     bool    `parquet:"name=sex, type=BOOLEAN"`
 	Day     int32   `parquet:"name=day, type=INT32, convertedtype=DATE"`
-	Ignored *int32  `parquet:"name=ignored, type=INT32"`
+	Ignored *int32  `parquet:"name=ignored, type=INT32"` // Pointers are OPTIONAL: nil is stored as null and reads back as nil, not zero
 	// Added field for record-level ETL metadata
 	RecordInfo `json:"_recordinfo" parquet:"name=_recordinfo, type=MAP, keytype=BYTE_ARRAY, keyconvertedtype=UTF8"`
 }
 
 type RecordInfo struct {
-	RawData         string `json:"_raw_data" parquet:"name=_raw_data, type=BYTE_ARRAY, ConvertedType=UTF8"`
-	RowHash         string `json:"_row_hash" parquet:"name=_row_hash, type=BYTE_ARRAY, ConvertedType=UTF8"`
+	RawData         string `json:"_raw_data" parquet:"name=_raw_data, type=BYTE_ARRAY, convertedtype=UTF8"`
+	RowHash         string `json:"_row_hash" parquet:"name=_row_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
 	IngestTimestamp int64  `json:"_ingest_timestamp" parquet:"name=_ingest_timestamp, type=INT64, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MILLIS"`
-	SourceInfo      string `json:"_source_info" parquet:"name=_source_info, type=BYTE_ARRAY, ConvertedType=UTF8"`
+	SourceInfo      string `json:"_source_info" parquet:"name=_source_info, type=BYTE_ARRAY, convertedtype=UTF8"`
 }
 
 // DataFrame is a generic container for tabular data
@@ -61,264 +80,2825 @@ func CreateDataFrame[T any](records []T) *DataFrame[T] {
 	}
 }
 
+// Count returns the number of records. It is safe to call on a nil DataFrame, which has none.
+func (df *DataFrame[T]) Count() int {
+	if df == nil {
+		return 0
+	}
+	return len(df.Records)
+}
+
+// IsEmpty reports whether the DataFrame has no records. A nil DataFrame is empty.
+func (df *DataFrame[T]) IsEmpty() bool {
+	return df.Count() == 0
+}
+
+// Append adds records to the end of the DataFrame and returns it for chaining.
+// The receiver must be non-nil; use CreateDataFrame to start an empty frame.
+func (df *DataFrame[T]) Append(records ...T) *DataFrame[T] {
+	df.Records = append(df.Records, records...)
+	return df
+}
+
+// Filter returns a new DataFrame containing only the records for which pred returns true.
+// Record order is preserved and the original DataFrame is left untouched.
+func (df *DataFrame[T]) Filter(pred func(T) bool) *DataFrame[T] {
+	filtered := make([]T, 0)
+	for _, record := range df.Records {
+		if pred(record) {
+			filtered = append(filtered, record)
+		}
+	}
+
+	return &DataFrame[T]{
+		Records: filtered,
+		schema:  df.schema,
+	}
+}
+
+// Validate runs every rule against every record and collects the failures without
+// stopping. A record that breaks several rules is reported once per rule, in rule order.
+// A nil result means all records passed.
+func (df *DataFrame[T]) Validate(rules ...func(T) error) []RecordError {
+	var errs []RecordError
+	for i, record := range df.Records {
+		for _, rule := range rules {
+			if err := rule(record); err != nil {
+				errs = append(errs, RecordError{Index: i, Err: err})
+			}
+		}
+	}
+	return errs
+}
+
+// Where is an alias for Filter that reads naturally in a query chain such as
+// df.Where(pred).Limit(n).
+func (df *DataFrame[T]) Where(pred func(T) bool) *DataFrame[T] {
+	return df.Filter(pred)
+}
+
+// Select projects each record onto the named fields of its JSON representation. Go cannot
+// narrow T to a struct with fewer fields, so the result holds maps decoded from JSON, with
+// numbers as float64 and nested objects as map[string]interface{}.
+func (df *DataFrame[T]) Select(fields ...string) (*DataFrame[map[string]interface{}], error) {
+	selected := make([]map[string]interface{}, len(df.Records))
+	for i, record := range df.Records {
+		projected, err := selectJSONFields(record, i, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(projected))
+		for field, raw := range projected {
+			var value interface{}
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("failed to decode field '%s' of record at index %d: %w", field, i, err)
+			}
+			row[field] = value
+		}
+		selected[i] = row
+	}
+
+	return CreateDataFrame(selected), nil
+}
+
+// Limit returns a new DataFrame with at most n records. It is an alias for Head.
+func (df *DataFrame[T]) Limit(n int) *DataFrame[T] {
+	return df.Head(n)
+}
+
+// Head returns a new DataFrame with the first n records. n is clamped to the
+// number of records, and a negative n is treated as zero.
+func (df *DataFrame[T]) Head(n int) *DataFrame[T] {
+	n = max(0, min(n, len(df.Records)))
+
+	return &DataFrame[T]{
+		Records: append(make([]T, 0, n), df.Records[:n]...),
+		schema:  df.schema,
+	}
+}
+
+// Tail returns a new DataFrame with the last n records. n is clamped to the
+// number of records, and a negative n is treated as zero.
+func (df *DataFrame[T]) Tail(n int) *DataFrame[T] {
+	n = max(0, min(n, len(df.Records)))
+
+	return &DataFrame[T]{
+		Records: append(make([]T, 0, n), df.Records[len(df.Records)-n:]...),
+		schema:  df.schema,
+	}
+}
+
+// Chunk splits the DataFrame into new DataFrames of at most size records each, in order,
+// e.g. to upload a large frame as several files. Only the last chunk may be smaller. A size
+// of zero or less returns all records as a single chunk, and an empty DataFrame returns no
+// chunks.
+func (df *DataFrame[T]) Chunk(size int) []*DataFrame[T] {
+	if size <= 0 {
+		size = len(df.Records)
+	}
+
+	var chunks []*DataFrame[T]
+	for start := 0; start < len(df.Records); start += size {
+		end := min(start+size, len(df.Records))
+		chunks = append(chunks, &DataFrame[T]{
+			Records: append(make([]T, 0, end-start), df.Records[start:end]...),
+			schema:  df.schema,
+		})
+	}
+	return chunks
+}
+
+// Sample returns a new DataFrame with up to n records chosen at random without replacement,
+// in random order. The same seed always gives the same sample of the same DataFrame. When n
+// is at least the number of records, all records are returned shuffled; a negative n is
+// treated as zero.
+func (df *DataFrame[T]) Sample(n int, seed int64) *DataFrame[T] {
+	n = max(0, min(n, len(df.Records)))
+
+	rng := rand.New(rand.NewSource(seed))
+	sampled := make([]T, n)
+	for i, idx := range rng.Perm(len(df.Records))[:n] {
+		sampled[i] = df.Records[idx]
+	}
+
+	return &DataFrame[T]{
+		Records: sampled,
+		schema:  df.schema,
+	}
+}
+
+// WithoutRecordInfo returns a copy of the DataFrame whose records omit every top-level
+// RecordInfo field, such as the embedded ETL metadata with its full raw data. The records are
+// values of a struct type built at runtime with the remaining exported fields and their tags,
+// so the result can be passed to any writer but not converted back to T.
+func (df *DataFrame[T]) WithoutRecordInfo() (*DataFrame[interface{}], error) {
+	var empty T
+	t := reflect.TypeOf(empty)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("slim output requires a struct type, got %T", empty)
+	}
+
+	recordInfoType := reflect.TypeOf(RecordInfo{})
+	var fields []reflect.StructField
+	var kept []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type == recordInfoType || !field.IsExported() {
+			continue
+		}
+		// reflect.StructOf cannot promote methods of embedded fields
+		if field.Anonymous && reflect.PointerTo(field.Type).NumMethod() > 0 {
+			return nil, fmt.Errorf("slim output does not support embedded field '%s' with methods", field.Name)
+		}
+		fields = append(fields, field)
+		kept = append(kept, i)
+	}
+	slimType := reflect.StructOf(fields)
+
+	records := make([]interface{}, len(df.Records))
+	for i, record := range df.Records {
+		src := reflect.ValueOf(record)
+		dst := reflect.New(slimType).Elem()
+		for j, idx := range kept {
+			dst.Field(j).Set(src.Field(idx))
+		}
+		records[i] = dst.Interface()
+	}
+
+	return &DataFrame[interface{}]{
+		Records: records,
+		schema:  reflect.New(slimType).Interface(),
+	}, nil
+}
+
+// Stream sends each record on the returned channel from a background goroutine. The channel
+// is closed once all records are sent or ctx is cancelled, so consumers that stop early must
+// cancel ctx to release the goroutine.
+func (df *DataFrame[T]) Stream(ctx context.Context) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, record := range df.Records {
+			select {
+			case out <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// ForEach calls fn for each record in order, checking ctx between records. It stops at the
+// first error from fn and returns it as a RecordError, or returns ctx.Err() if ctx is
+// cancelled before all records are visited.
+func (df *DataFrame[T]) ForEach(ctx context.Context, fn func(ctx context.Context, rec T) error) error {
+	for i, record := range df.Records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(ctx, record); err != nil {
+			return RecordError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+// ForEachParallel calls fn for each record using a pool of workers goroutines, in no particular
+// order. The first error from fn cancels the context passed to the remaining calls and is
+// returned as a RecordError; if ctx is cancelled first, ctx.Err() is returned. All workers
+// have exited by the time ForEachParallel returns.
+func (df *DataFrame[T]) ForEachParallel(ctx context.Context, workers int, fn func(context.Context, T) error) error {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(ctx, df.Records[i]); err != nil {
+					errOnce.Do(func() {
+						firstErr = RecordError{Index: i, Err: err}
+						cancel() // Stop dispatching and signal in-flight calls
+					})
+				}
+			}
+		}()
+	}
+
+	// Dispatch record indexes until done or cancelled
+dispatch:
+	for i := range df.Records {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// Map applies fn to every record and returns a new DataFrame of the resulting type.
+// It is a package-level function because Go methods cannot introduce new type parameters.
+func Map[T any, U any](df *DataFrame[T], fn func(T) U) *DataFrame[U] {
+	if df == nil {
+		return CreateDataFrame(make([]U, 0))
+	}
+
+	mapped := make([]U, len(df.Records))
+	for i, record := range df.Records {
+		mapped[i] = fn(record)
+	}
+
+	return CreateDataFrame(mapped)
+}
+
+// Concat merges the records of several DataFrames, in argument order, into a new DataFrame.
+// Nil DataFrames are skipped and the schema reference is taken from the first non-nil one.
+func Concat[T any](dfs ...*DataFrame[T]) *DataFrame[T] {
+	var schema interface{}
+	total := 0
+	for _, df := range dfs {
+		if df == nil {
+			continue
+		}
+		if schema == nil {
+			schema = df.schema
+		}
+		total += len(df.Records)
+	}
+
+	if schema == nil {
+		return CreateDataFrame(make([]T, 0))
+	}
+
+	records := make([]T, 0, total)
+	for _, df := range dfs {
+		if df == nil {
+			continue
+		}
+		records = append(records, df.Records...)
+	}
+
+	return &DataFrame[T]{
+		Records: records,
+		schema:  schema,
+	}
+}
+
+// JoinType selects which left records MergeByKey keeps
+type JoinType int
+
+const (
+	// InnerJoin keeps only left records with at least one matching right record
+	InnerJoin JoinType = iota
+	// LeftJoin keeps every left record, unchanged when nothing matches
+	LeftJoin
+)
+
+// MergeByKey joins left with right on the keys returned by leftKey and rightKey. Every right
+// record matching a left record is folded into it with combine, in right order. Left records
+// without a match are dropped for InnerJoin and kept unchanged for LeftJoin. Left order is
+// preserved and neither input is modified.
+func MergeByKey[L any, R any](left *DataFrame[L], right *DataFrame[R], leftKey func(L) string, rightKey func(R) string, combine func(L, R) L, how JoinType) *DataFrame[L] {
+	matches := make(map[string][]R)
+	for _, record := range right.Records {
+		key := rightKey(record)
+		matches[key] = append(matches[key], record)
+	}
+
+	merged := make([]L, 0, len(left.Records))
+	for _, record := range left.Records {
+		rights, ok := matches[leftKey(record)]
+		if !ok && how == InnerJoin {
+			continue
+		}
+		for _, r := range rights {
+			record = combine(record, r)
+		}
+		merged = append(merged, record)
+	}
+
+	return &DataFrame[L]{
+		Records: merged,
+		schema:  left.schema,
+	}
+}
+
+// Sort sorts the DataFrame records in place using less. The sort is stable, so records
+// that compare equal keep their existing relative order.
+func (df *DataFrame[T]) Sort(less func(a, b T) bool) {
+	sort.SliceStable(df.Records, func(i, j int) bool {
+		return less(df.Records[i], df.Records[j])
+	})
+}
+
+// Sorted returns a new DataFrame with the records stably sorted using less,
+// leaving the original DataFrame untouched.
+func (df *DataFrame[T]) Sorted(less func(a, b T) bool) *DataFrame[T] {
+	records := make([]T, len(df.Records))
+	copy(records, df.Records)
+
+	sorted := &DataFrame[T]{
+		Records: records,
+		schema:  df.schema,
+	}
+	sorted.Sort(less)
+	return sorted
+}
+
+// Dedup returns a new DataFrame keeping only the first record for each key returned by keyFn.
+// Record order is preserved, e.g. keyFn can return RecordInfo.RowHash to drop repeated rows.
+func (df *DataFrame[T]) Dedup(keyFn func(T) string) *DataFrame[T] {
+	seen := make(map[string]struct{}, len(df.Records))
+	unique := make([]T, 0)
+	for _, record := range df.Records {
+		key := keyFn(record)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, record)
+	}
+
+	return &DataFrame[T]{
+		Records: unique,
+		schema:  df.schema,
+	}
+}
+
+// ColumnStats holds summary statistics for a numeric column
+type ColumnStats struct {
+	Count int // Number of non-nil values
+	Min   float64
+	Max   float64
+	Mean  float64
+	Sum   float64
+}
+
+// Describe computes summary statistics for every numeric (int, uint or float) field of T,
+// keyed by field name. Fields of embedded structs are included and nil pointers are not
+// counted. Non-numeric fields are skipped.
+func (df *DataFrame[T]) Describe() (map[string]ColumnStats, error) {
+	var empty T
+	t := reflect.TypeOf(empty)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("summary statistics require a struct type, got %T", empty)
+	}
+
+	stats := make(map[string]ColumnStats)
+	for _, col := range fieldColumnsFor(t) {
+		field := t.FieldByIndex(col.Index)
+		kind := field.Type.Kind()
+		if kind == reflect.Ptr {
+			kind = field.Type.Elem().Kind()
+		}
+		if !isNumericKind(kind) {
+			continue
+		}
+
+		var cs ColumnStats
+		for _, record := range df.Records {
+			v := reflect.ValueOf(record).FieldByIndex(col.Index)
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					continue
+				}
+				v = v.Elem()
+			}
+
+			x := numericValue(v)
+			if cs.Count == 0 || x < cs.Min {
+				cs.Min = x
+			}
+			if cs.Count == 0 || x > cs.Max {
+				cs.Max = x
+			}
+			cs.Sum += x
+			cs.Count++
+		}
+		if cs.Count > 0 {
+			cs.Mean = cs.Sum / float64(cs.Count)
+		}
+		stats[field.Name] = cs
+	}
+
+	return stats, nil
+}
+
+// isNumericKind reports whether k is an integer or floating-point kind
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// numericValue converts a numeric reflect.Value to float64
+func numericValue(v reflect.Value) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
 // ParquetWriterConfig holds configuration for Parquet writing
 type ParquetWriterConfig struct {
-	Compression parquet.CompressionCodec
-	Concurrency int64
+	Compression  parquet.CompressionCodec
+	Concurrency  int64
+	RowGroupSize int64  // Target row group size in bytes; zero keeps the library default
+	SchemaName   string // Name of the root schema element; empty keeps the library default
+	// Encodings overrides the encoding of individual leaf columns, keyed by their dotted path in
+	// the file schema (e.g. "name" or "_recordinfo._raw_data"). Columns not listed keep the
+	// encoding from their struct tag.
+	Encodings map[string]parquet.Encoding
+	// OmitStats drops the min/max and null count statistics the writer otherwise records for
+	// every column in page headers and the footer. Readers such as Spark use them to skip row
+	// groups, so only set this when smaller files matter more than predicate pushdown.
+	OmitStats bool
+}
+
+// DefaultParquetConfig returns the default configuration
+func DefaultParquetConfig() ParquetWriterConfig {
+	return ParquetWriterConfig{
+		Compression:  parquet.CompressionCodec_SNAPPY,
+		Concurrency:  4,
+		RowGroupSize: 128 * 1024 * 1024, // 128MB
+	}
+}
+
+// ZstdParquetConfig returns the default configuration with Zstandard compression, which
+// trades some CPU for noticeably smaller files on string-heavy columns such as RawData
+func ZstdParquetConfig() ParquetWriterConfig {
+	config := DefaultParquetConfig()
+	config.Compression = parquet.CompressionCodec_ZSTD
+	return config
+}
+
+// newParquetWriter creates a parquet writer for the given schema and applies the config to it
+func newParquetWriter(fw source.ParquetFile, schema interface{}, config ParquetWriterConfig) (*writer.ParquetWriter, error) {
+	// Create the parquet writer
+	pw, err := writer.NewParquetWriter(fw, schema, config.Concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+
+	if err := applyParquetConfig(pw, config); err != nil {
+		// fw belongs to the caller, who closes it
+		_ = pw.WriteStop()
+		return nil, err
+	}
+	return pw, nil
+}
+
+// applyParquetConfig applies the compression, row group size, schema name, statistics and
+// encoding settings to pw
+func applyParquetConfig(pw *writer.ParquetWriter, config ParquetWriterConfig) error {
+	// Set compression
+	pw.CompressionType = config.Compression
+
+	// Set row group size, keeping the library default when unset
+	if config.RowGroupSize > 0 {
+		pw.RowGroupSize = config.RowGroupSize
+	}
+
+	// Set the root schema name, which the writer applies to the footer on WriteStop
+	if config.SchemaName != "" {
+		pw.SchemaHandler.Infos[0].ExName = config.SchemaName
+	}
+
+	// Like encodings, the writer reads this per column when it builds pages
+	if config.OmitStats {
+		for _, info := range pw.SchemaHandler.Infos {
+			info.OmitStats = true
+		}
+	}
+
+	return applyEncodings(pw.SchemaHandler, config.Encodings)
 }
 
-// DefaultParquetConfig returns the default configuration
-func DefaultParquetConfig() ParquetWriterConfig {
-	return ParquetWriterConfig{
-		Compression: parquet.CompressionCodec_SNAPPY,
-		Concurrency: 4,
+// leafColumn identifies a leaf column by its dotted path in the file schema and its index
+// in the schema elements
+type leafColumn struct {
+	path  string
+	index int
+}
+
+// leafColumns returns the leaf columns of sh in schema order, which is also the order of
+// the column chunks in each row group
+func leafColumns(sh *schema.SchemaHandler) []leafColumn {
+	var leaves []leafColumn
+	for i, element := range sh.SchemaElements {
+		if i == 0 || element.GetNumChildren() > 0 {
+			continue
+		}
+		// External paths start with the root element name, which is not part of the column path
+		exPath := common.StrToPath(sh.InPathToExPath[sh.IndexMap[int32(i)]])
+		leaves = append(leaves, leafColumn{path: strings.Join(exPath[1:], "."), index: i})
+	}
+	return leaves
+}
+
+// applyEncodings sets the encoding of the leaf columns named in encodings. The writer reads
+// each column's encoding from the schema handler when it builds pages, so this must run
+// before any record is written.
+func applyEncodings(sh *schema.SchemaHandler, encodings map[string]parquet.Encoding) error {
+	if len(encodings) == 0 {
+		return nil
+	}
+
+	leaves := make(map[string]int)
+	for _, leaf := range leafColumns(sh) {
+		leaves[leaf.path] = leaf.index
+	}
+
+	for column, encoding := range encodings {
+		i, ok := leaves[column]
+		if !ok {
+			return fmt.Errorf("cannot set encoding of column '%s': no such leaf column in schema", column)
+		}
+		sh.Infos[i].Encoding = encoding
+	}
+	return nil
+}
+
+// SchemaJSONFor returns the Parquet schema inferred from T's struct tags in the xitongsys
+// JSON schema format, without writing any data. It fails on invalid tags, so it can be used
+// to check a struct before writing.
+func SchemaJSONFor[T any]() (string, error) {
+	var empty T
+	sh, err := schema.NewSchemaHandlerFromStruct(&empty)
+	if err != nil {
+		return "", fmt.Errorf("failed to infer parquet schema for type %T: %w", empty, err)
+	}
+
+	root, _ := schemaJSONItem(sh, 0)
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal parquet schema: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// schemaJSONItem converts the schema element at index i and its descendants into a JSON
+// schema item, returning the item and the index of the next sibling element
+func schemaJSONItem(sh *schema.SchemaHandler, i int) (*schema.JSONSchemaItemType, int) {
+	element := sh.SchemaElements[i]
+	info := sh.Infos[i]
+
+	tags := []string{"name=" + info.ExName, "inname=" + info.InName}
+	switch {
+	case element.GetNumChildren() == 0:
+		tags = append(tags, "type="+element.GetType().String())
+		if element.IsSetConvertedType() {
+			tags = append(tags, "convertedtype="+element.GetConvertedType().String())
+		}
+		if element.IsSetTypeLength() && element.GetTypeLength() > 0 {
+			tags = append(tags, fmt.Sprintf("length=%d", element.GetTypeLength()))
+		}
+		if element.IsSetScale() {
+			tags = append(tags, fmt.Sprintf("scale=%d", element.GetScale()))
+		}
+		if element.IsSetPrecision() {
+			tags = append(tags, fmt.Sprintf("precision=%d", element.GetPrecision()))
+		}
+		keys := make([]string, 0, len(info.LogicalTypeFields))
+		for key := range info.LogicalTypeFields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			tags = append(tags, key+"="+info.LogicalTypeFields[key])
+		}
+	case element.GetConvertedType() == parquet.ConvertedType_LIST:
+		tags = append(tags, "type=LIST")
+	case element.GetConvertedType() == parquet.ConvertedType_MAP:
+		tags = append(tags, "type=MAP")
+	}
+	tags = append(tags, "repetitiontype="+element.GetRepetitionType().String())
+
+	item := &schema.JSONSchemaItemType{Tag: strings.Join(tags, ", ")}
+	next := i + 1
+	children := element.GetNumChildren()
+
+	// LIST and MAP items list the element or key/value fields of their repeated group directly
+	if element.GetConvertedType() == parquet.ConvertedType_LIST || element.GetConvertedType() == parquet.ConvertedType_MAP {
+		children = sh.SchemaElements[next].GetNumChildren()
+		next++
+	}
+	for c := int32(0); c < children; c++ {
+		var child *schema.JSONSchemaItemType
+		child, next = schemaJSONItem(sh, next)
+		item.Fields = append(item.Fields, child)
+	}
+
+	return item, next
+}
+
+// timeType is the reflect.Type of time.Time, which parquet-go cannot map to a column itself
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeColumn is a top-level time.Time or *time.Time field stored as an INT64 timestamp
+type timeColumn struct {
+	unit    string // MILLIS, MICROS or NANOS
+	pointer bool   // Whether the field is a *time.Time, stored as an OPTIONAL *int64
+}
+
+// timeCodec converts records with time.Time fields to and from a storage struct type in
+// which those fields are int64 timestamps that parquet-go can write and read
+type timeCodec struct {
+	recordType  reflect.Type
+	storageType reflect.Type
+	fields      []int              // Exported field indexes of recordType, in storage order
+	columns     map[int]timeColumn // Timestamp columns by storage field index
+}
+
+// timeCodecFor returns the codec for t, or nil if t has no parquet-tagged time.Time fields.
+// Such fields must be tagged type=INT64 with a TIMESTAMP logical or converted type, e.g.
+// `parquet:"name=created_at, type=INT64, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MILLIS"`.
+func timeCodecFor(t reflect.Type) (*timeCodec, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	codec := &timeCodec{recordType: t, columns: make(map[int]timeColumn)}
+	var storageFields []reflect.StructField
+	var methodField string // First embedded field whose methods reflect.StructOf cannot promote
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, tagged := field.Tag.Lookup("parquet")
+		pointer := field.Type.Kind() == reflect.Pointer && field.Type.Elem() == timeType
+		if tagged && (field.Type == timeType || pointer) {
+			unit, err := timestampUnit(tag)
+			if err != nil {
+				return nil, fmt.Errorf("time.Time field '%s': %w", field.Name, err)
+			}
+			codec.columns[len(storageFields)] = timeColumn{unit: unit, pointer: pointer}
+			field.Type = reflect.TypeOf(int64(0))
+			if pointer {
+				field.Type = reflect.TypeOf((*int64)(nil))
+			}
+		} else if field.Anonymous && reflect.PointerTo(field.Type).NumMethod() > 0 && methodField == "" {
+			methodField = field.Name
+		}
+
+		storageFields = append(storageFields, field)
+		codec.fields = append(codec.fields, i)
+	}
+	if len(codec.columns) == 0 {
+		return nil, nil
+	}
+	// reflect.StructOf cannot promote methods of embedded fields
+	if methodField != "" {
+		return nil, fmt.Errorf("time.Time fields are not supported alongside embedded field '%s' with methods", methodField)
+	}
+
+	codec.storageType = reflect.StructOf(storageFields)
+	return codec, nil
+}
+
+// timestampUnit returns the unit of the INT64 timestamp described by a parquet struct tag
+func timestampUnit(tag string) (string, error) {
+	values := make(map[string]string)
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		values[strings.ToLower(strings.TrimSpace(key))] = strings.ToUpper(strings.TrimSpace(value))
+	}
+	if values["type"] != "INT64" {
+		return "", fmt.Errorf("must be tagged type=INT64, got type=%s", values["type"])
+	}
+
+	switch {
+	case values["logicaltype"] == "TIMESTAMP":
+		switch unit := values["logicaltype.unit"]; unit {
+		case "MILLIS", "MICROS", "NANOS":
+			return unit, nil
+		case "":
+			return "MILLIS", nil
+		default:
+			return "", fmt.Errorf("unknown timestamp unit %s", unit)
+		}
+	case values["convertedtype"] == "TIMESTAMP_MILLIS":
+		return "MILLIS", nil
+	case values["convertedtype"] == "TIMESTAMP_MICROS":
+		return "MICROS", nil
+	}
+	return "", fmt.Errorf("must be tagged with a TIMESTAMP logical type or converted type")
+}
+
+// toStorage converts record to a value of the storage type
+func (c *timeCodec) toStorage(record reflect.Value) reflect.Value {
+	storage := reflect.New(c.storageType).Elem()
+	for j, i := range c.fields {
+		src, dst := record.Field(i), storage.Field(j)
+		column, ok := c.columns[j]
+		switch {
+		case !ok:
+			dst.Set(src)
+		case column.pointer:
+			if !src.IsNil() {
+				ts := toTimestamp(src.Elem().Interface().(time.Time), column.unit)
+				dst.Set(reflect.ValueOf(&ts))
+			}
+		default:
+			dst.SetInt(toTimestamp(src.Interface().(time.Time), column.unit))
+		}
+	}
+	return storage
+}
+
+// fromStorage converts a value of the storage type back to a record. Timestamps are
+// returned in UTC.
+func (c *timeCodec) fromStorage(storage reflect.Value) reflect.Value {
+	record := reflect.New(c.recordType).Elem()
+	for j, i := range c.fields {
+		src, dst := storage.Field(j), record.Field(i)
+		column, ok := c.columns[j]
+		switch {
+		case !ok:
+			dst.Set(src)
+		case column.pointer:
+			if !src.IsNil() {
+				t := fromTimestamp(src.Elem().Int(), column.unit)
+				dst.Set(reflect.ValueOf(&t))
+			}
+		default:
+			dst.Set(reflect.ValueOf(fromTimestamp(src.Int(), column.unit)))
+		}
+	}
+	return record
+}
+
+// storageRow returns record as parquet-go should write it: converted to the storage type,
+// or unchanged when c is nil
+func (c *timeCodec) storageRow(record interface{}) interface{} {
+	if c == nil {
+		return record
+	}
+	return c.toStorage(reflect.ValueOf(record)).Interface()
+}
+
+// toTimestamp converts t to a timestamp in the given unit since the Unix epoch
+func toTimestamp(t time.Time, unit string) int64 {
+	switch unit {
+	case "MICROS":
+		return t.UnixMicro()
+	case "NANOS":
+		return t.UnixNano()
+	default:
+		return t.UnixMilli()
+	}
+}
+
+// fromTimestamp converts a timestamp in the given unit since the Unix epoch to a UTC time
+func fromTimestamp(ts int64, unit string) time.Time {
+	switch unit {
+	case "MICROS":
+		return time.UnixMicro(ts).UTC()
+	case "NANOS":
+		return time.Unix(0, ts).UTC()
+	default:
+		return time.UnixMilli(ts).UTC()
+	}
+}
+
+// WriteToParquet writes the DataFrame to a Parquet file using the provided writer
+func (df *DataFrame[T]) WriteToParquet(fw source.ParquetFile, config ParquetWriterConfig) error {
+	return df.WriteToParquetContext(context.Background(), fw, config)
+}
+
+// WriteToParquetContext writes the DataFrame to a Parquet file, checking ctx between records.
+// When ctx is cancelled the writer is stopped and ctx.Err() is returned.
+// Top-level time.Time fields tagged as INT64 TIMESTAMP columns are written as timestamps in
+// the tagged unit.
+func (df *DataFrame[T]) WriteToParquetContext(ctx context.Context, fw source.ParquetFile, config ParquetWriterConfig) error {
+	var empty T
+	codec, err := timeCodecFor(reflect.TypeOf(empty))
+	if err != nil {
+		return fmt.Errorf("failed to infer parquet schema for type %T: %w", empty, err)
+	}
+	schema := df.schema
+	if codec != nil {
+		schema = reflect.New(codec.storageType).Interface()
+	}
+
+	pw, err := newParquetWriter(fw, schema, config)
+	if err != nil {
+		return err
+	}
+
+	// Write each record
+	for i, record := range df.Records {
+		if err := ctx.Err(); err != nil {
+			_ = pw.WriteStop()
+			return err
+		}
+		if err := pw.Write(codec.storageRow(record)); err != nil {
+			_ = pw.WriteStop()
+			return fmt.Errorf("failed to write record at index %d: %w", i, err)
+		}
+	}
+
+	// Finalize writing
+	if err := pw.WriteStop(); err != nil {
+		return fmt.Errorf("failed to finalize parquet file: %w", err)
+	}
+
+	return nil
+}
+
+// RecordError describes a single record that could not be processed
+type RecordError struct {
+	Index int   // Position of the record in the DataFrame
+	Err   error // Underlying cause
+}
+
+func (e RecordError) Error() string {
+	return fmt.Sprintf("record at index %d: %v", e.Index, e.Err)
+}
+
+func (e RecordError) Unwrap() error {
+	return e.Err
+}
+
+// WriteToParquetLenient writes the DataFrame to a Parquet file, skipping records that cannot
+// be serialized instead of aborting. Each record is marshalled on its own before being queued,
+// so this is slower than WriteToParquet. Skipped records are reported in errs; err is reserved
+// for fatal writer creation, flush and finalize failures. time.Time fields are written as
+// in WriteToParquetContext.
+func (df *DataFrame[T]) WriteToParquetLenient(fw source.ParquetFile, config ParquetWriterConfig) (writtenCount int, errs []RecordError, err error) {
+	var empty T
+	codec, err := timeCodecFor(reflect.TypeOf(empty))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to infer parquet schema for type %T: %w", empty, err)
+	}
+	schema := df.schema
+	if codec != nil {
+		schema = reflect.New(codec.storageType).Interface()
+	}
+
+	pw, err := newParquetWriter(fw, schema, config)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for i, record := range df.Records {
+		row := codec.storageRow(record)
+		// Dry-run marshalling so a bad record can't poison the row group it would be flushed with
+		if _, err := pw.MarshalFunc([]interface{}{row}, pw.SchemaHandler); err != nil {
+			errs = append(errs, RecordError{Index: i, Err: err})
+			continue
+		}
+		if err := pw.Write(row); err != nil {
+			_ = pw.WriteStop()
+			return writtenCount, errs, fmt.Errorf("failed to write record at index %d: %w", i, err)
+		}
+		writtenCount++
+	}
+
+	// Finalize writing
+	if err := pw.WriteStop(); err != nil {
+		return writtenCount, errs, fmt.Errorf("failed to finalize parquet file: %w", err)
+	}
+
+	return writtenCount, errs, nil
+}
+
+// WriteParquetStream writes records received from a channel to a Parquet file without holding
+// them all in memory. It returns once the channel is closed and the file is finalized. On a write
+// error it returns without draining the channel, so producers should stop sending (e.g. via context).
+// time.Time fields are written as in WriteToParquetContext.
+func WriteParquetStream[T any](fw source.ParquetFile, records <-chan T, config ParquetWriterConfig) error {
+	// Create an empty instance for schema reference
+	var empty T
+	var schema interface{} = &empty
+
+	codec, err := timeCodecFor(reflect.TypeOf(empty))
+	if err != nil {
+		return fmt.Errorf("failed to infer parquet schema for type %T: %w", empty, err)
+	}
+	if codec != nil {
+		schema = reflect.New(codec.storageType).Interface()
+	}
+
+	pw, err := newParquetWriter(fw, schema, config)
+	if err != nil {
+		return err
+	}
+
+	// Write each record as it arrives
+	i := 0
+	for record := range records {
+		if err := pw.Write(codec.storageRow(record)); err != nil {
+			_ = pw.WriteStop()
+			return fmt.Errorf("failed to write record at index %d: %w", i, err)
+		}
+		i++
+	}
+
+	// Finalize writing
+	if err := pw.WriteStop(); err != nil {
+		return fmt.Errorf("failed to finalize parquet file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteToLocalParquet writes the DataFrame to a local Parquet file. The file only appears at
+// filePath once it is complete, and a failed write leaves any existing file untouched.
+func (df *DataFrame[T]) WriteToLocalParquet(filePath string, config ...ParquetWriterConfig) error {
+	return df.WriteToLocalParquetContext(context.Background(), filePath, config...)
+}
+
+// WriteToLocalParquetContext writes the DataFrame to a local Parquet file, aborting with
+// ctx.Err() if ctx is cancelled before all records are written
+func (df *DataFrame[T]) WriteToLocalParquetContext(ctx context.Context, filePath string, config ...ParquetWriterConfig) error {
+	// Use provided config or default
+	cfg := DefaultParquetConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	return df.Write(ctx, LocalSink{Path: filePath}, cfg)
+}
+
+// WriteToLocalParquetIfChanged writes the DataFrame to a local Parquet file unless the file
+// already holds the same records, as recorded by the content hash in the sidecar file
+// filePath + ".sha256". It reports whether the file was written, and updates the sidecar
+// after each write. The hash ignores RecordInfo.IngestTimestamp, so re-ingesting identical
+// data is skipped, but it does not cover config: changing only the compression is a no-op.
+func (df *DataFrame[T]) WriteToLocalParquetIfChanged(filePath string, config ...ParquetWriterConfig) (bool, error) {
+	hash, err := df.contentHash()
+	if err != nil {
+		return false, err
+	}
+
+	sidecar := filePath + ".sha256"
+	if previous, err := os.ReadFile(sidecar); err == nil && strings.TrimSpace(string(previous)) == hash {
+		if _, err := os.Stat(filePath); err == nil {
+			return false, nil
+		}
+	}
+
+	if err := df.WriteToLocalParquet(filePath, config...); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(sidecar, []byte(hash+"\n"), 0644); err != nil {
+		return true, fmt.Errorf("failed to write content hash to '%s': %w", sidecar, err)
+	}
+
+	return true, nil
+}
+
+// contentHash returns the SHA-256 hex digest of the records' JSON encoding, in order, with
+// RecordInfo.IngestTimestamp cleared
+func (df *DataFrame[T]) contentHash() (string, error) {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for i, record := range df.Records {
+		if f, err := recordInfoField(&record); err == nil {
+			f.FieldByName("IngestTimestamp").SetInt(0)
+		}
+		if err := enc.Encode(record); err != nil {
+			return "", fmt.Errorf("failed to marshal record at index %d: %w", i, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteToLocalParquetSlim writes the DataFrame to a local Parquet file without the RecordInfo
+// metadata, see WithoutRecordInfo. Read the file back with a type that has no RecordInfo
+// field, or select its columns with ReadFromLocalParquetColumns.
+func (df *DataFrame[T]) WriteToLocalParquetSlim(filePath string, config ...ParquetWriterConfig) error {
+	slim, err := df.WithoutRecordInfo()
+	if err != nil {
+		return err
+	}
+	return slim.WriteToLocalParquet(filePath, config...)
+}
+
+// ToParquetBytes writes the DataFrame to an in-memory Parquet file and returns its bytes
+func (df *DataFrame[T]) ToParquetBytes(config ...ParquetWriterConfig) ([]byte, error) {
+	// Use provided config or default
+	cfg := DefaultParquetConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	sink := &BufferSink{}
+	if err := df.Write(context.Background(), sink, cfg); err != nil {
+		return nil, err
+	}
+
+	return sink.Bytes(), nil
+}
+
+// ParquetSink is a destination a DataFrame can be written to with Write. OpenWriter is called
+// once per write; the returned file is closed by Write, and a sink that finalizes on Close
+// (such as S3Sink) must report failures from it.
+type ParquetSink interface {
+	OpenWriter(ctx context.Context) (source.ParquetFile, error)
+}
+
+// LocalSink writes Parquet output to a local file, replacing any existing file at Path. The
+// output goes to a temporary file in the same directory that is renamed over Path only when
+// the writer is closed, so readers never see a partial file. The temporary file is removed
+// instead if the context passed to OpenWriter is cancelled first, as Write does on error.
+type LocalSink struct {
+	Path string
+}
+
+// OpenWriter creates the temporary file next to Path
+func (s LocalSink) OpenWriter(ctx context.Context) (source.ParquetFile, error) {
+	tmp, err := createLocalTemp(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local writer for path '%s': %w", s.Path, err)
+	}
+	// Keep the permissions of the file being replaced
+	if info, err := os.Stat(s.Path); err == nil {
+		if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return nil, fmt.Errorf("failed to create local writer for path '%s': %w", s.Path, err)
+		}
+	}
+
+	return &atomicLocalFile{
+		LocalFile: local.LocalFile{FilePath: tmp.Name(), File: tmp},
+		ctx:       ctx,
+		path:      s.Path,
+	}, nil
+}
+
+// createLocalTemp creates a uniquely named hidden file next to path. Unlike os.CreateTemp,
+// which always uses mode 0600, the file is created with mode 0666 so the umask applies just
+// as it does for os.Create.
+func createLocalTemp(path string) (*os.File, error) {
+	dir, base := filepath.Split(path)
+	for try := 0; ; try++ {
+		name := filepath.Join(dir, "."+base+"."+strconv.FormatUint(uint64(rand.Uint32()), 10)+".tmp")
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) && try < 100 {
+			continue
+		}
+		return f, err
+	}
+}
+
+// atomicLocalFile is a local Parquet file written under a temporary name and moved to path
+// on Close, unless ctx has been cancelled
+type atomicLocalFile struct {
+	local.LocalFile
+	ctx  context.Context
+	path string
+}
+
+// Close renames the temporary file to its final path, or removes it if the write was abandoned
+func (f *atomicLocalFile) Close() error {
+	err := f.LocalFile.Close()
+	if err == nil {
+		err = f.ctx.Err()
+	}
+	if err == nil {
+		if err = os.Rename(f.FilePath, f.path); err != nil {
+			err = fmt.Errorf("failed to move parquet output into place at '%s': %w", f.path, err)
+		}
+	}
+	if err != nil {
+		os.Remove(f.FilePath)
+		return err
+	}
+	return nil
+}
+
+// S3Sink writes Parquet output to an S3 object. The upload is aborted if the context passed
+// to OpenWriter is cancelled before the writer is closed.
+type S3Sink struct {
+	Client  *awsS3.S3
+	Bucket  string
+	Key     string
+	Options S3WriteOptions
+}
+
+// OpenWriter starts the upload of the S3 object
+func (s S3Sink) OpenWriter(ctx context.Context) (source.ParquetFile, error) {
+	acl := s.Options.ACL
+	if acl == "" {
+		acl = awsS3.ObjectCannedACLPrivate
+	}
+	contentType := s.Options.ContentType
+	if contentType == "" {
+		contentType = ParquetContentType
+	}
+
+	uploaderOptions := []func(*s3manager.Uploader){withContentType(contentType)}
+	if s.Options.PartSize != 0 {
+		if s.Options.PartSize < s3manager.MinUploadPartSize {
+			return nil, fmt.Errorf("part size %d is below the S3 minimum of %d bytes", s.Options.PartSize, s3manager.MinUploadPartSize)
+		}
+		uploaderOptions = append(uploaderOptions, withPartSize(s.Options.PartSize))
+	}
+
+	// Create S3 file writer with custom client
+	fw, err := s3.NewS3FileWriterWithClient(ctx, s.Client, s.Bucket, s.Key, acl, uploaderOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 writer for bucket '%s' and key '%s': %w",
+			s.Bucket, s.Key, err)
+	}
+	return fw, nil
+}
+
+// BufferSink keeps Parquet output in memory. Bytes returns the output of the last write.
+type BufferSink struct {
+	file *buffer.BufferFile
+}
+
+// OpenWriter starts a new in-memory file, discarding any previous output
+func (s *BufferSink) OpenWriter(ctx context.Context) (source.ParquetFile, error) {
+	s.file = buffer.NewBufferFile()
+	return s.file, nil
+}
+
+// Bytes returns the Parquet file contents, or nil if nothing has been written
+func (s *BufferSink) Bytes() []byte {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Bytes()
+}
+
+// Write writes the DataFrame to sink, checking ctx between records. If writing fails the
+// context given to the sink is cancelled before the file is closed, so partial uploads are
+// aborted rather than completed.
+func (df *DataFrame[T]) Write(ctx context.Context, sink ParquetSink, config ParquetWriterConfig) error {
+	sinkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fw, err := sink.OpenWriter(sinkCtx)
+	if err != nil {
+		return err
+	}
+
+	if err := df.WriteToParquetContext(ctx, fw, config); err != nil {
+		cancel()
+		fw.Close()
+		return err
+	}
+
+	// Sinks such as S3 only finalize the output on Close, so its error must be checked
+	if err := fw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize parquet output: %w", err)
+	}
+
+	return nil
+}
+
+// WriteMapsToLocalParquet writes schemaless rows to a local Parquet file using an explicit
+// parquet-go JSON schema, for data without a compile-time Go struct. Map keys must match
+// the field names in schemaJSON. Like LocalSink, the file at filePath is only replaced once
+// every row has been written.
+func WriteMapsToLocalParquet(filePath, schemaJSON string, rows []map[string]interface{}, config ParquetWriterConfig) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fw, err := LocalSink{Path: filePath}.OpenWriter(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := writeMapsToParquet(fw, schemaJSON, rows, config); err != nil {
+		// Cancelling first makes Close remove the temporary file instead of moving it into place
+		cancel()
+		fw.Close()
+		return err
+	}
+
+	if err := fw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize parquet output: %w", err)
+	}
+
+	return nil
+}
+
+// writeMapsToParquet writes rows as JSON documents to fw against schemaJSON. fw is left open.
+func writeMapsToParquet(fw source.ParquetFile, schemaJSON string, rows []map[string]interface{}, config ParquetWriterConfig) error {
+	jw, err := writer.NewJSONWriter(schemaJSON, fw, config.Concurrency)
+	if err != nil {
+		return fmt.Errorf("failed to create parquet JSON writer: %w", err)
+	}
+	if err := applyParquetConfig(&jw.ParquetWriter, config); err != nil {
+		_ = jw.WriteStop()
+		return err
+	}
+
+	// Write each row as a JSON document
+	for i, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			_ = jw.WriteStop()
+			return fmt.Errorf("failed to marshal row at index %d: %w", i, err)
+		}
+		if err := jw.Write(string(data)); err != nil {
+			_ = jw.WriteStop()
+			return fmt.Errorf("failed to write row at index %d: %w", i, err)
+		}
+	}
+
+	// Finalize writing
+	if err := jw.WriteStop(); err != nil {
+		return fmt.Errorf("failed to finalize parquet file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteToLocalParquetPartitioned writes the DataFrame as Hive-style partitions under baseDir.
+// Records are grouped by the value returned from partitionFn (e.g. "dt=2024-01-01") and each
+// group is written to baseDir/<value>/part.parquet.
+func (df *DataFrame[T]) WriteToLocalParquetPartitioned(baseDir string, partitionFn func(T) string, config ...ParquetWriterConfig) error {
+	// Group records by partition value, keeping first-seen partition order
+	var partitions []string
+	groups := make(map[string][]T)
+	for _, record := range df.Records {
+		partition := partitionFn(record)
+		if _, ok := groups[partition]; !ok {
+			partitions = append(partitions, partition)
+		}
+		groups[partition] = append(groups[partition], record)
+	}
+
+	for _, partition := range partitions {
+		records := groups[partition]
+		if len(records) == 0 {
+			continue
+		}
+
+		dir := filepath.Join(baseDir, partition)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create partition directory '%s': %w", dir, err)
+		}
+
+		partDF := &DataFrame[T]{Records: records, schema: df.schema}
+		if err := partDF.WriteToLocalParquet(filepath.Join(dir, "part.parquet"), config...); err != nil {
+			return fmt.Errorf("failed to write partition '%s': %w", partition, err)
+		}
+	}
+
+	return nil
+}
+
+// PartitionByIngestDate returns a partition function for WriteToLocalParquetPartitioned that
+// routes records to "dt=YYYY-MM-DD" by the UTC date of their RecordInfo.IngestTimestamp.
+// Records without a RecordInfo field or with a zero timestamp use the UTC date of fallback.
+func PartitionByIngestDate[T any](fallback time.Time) func(T) string {
+	return func(record T) string {
+		date := fallback
+		if f, err := recordInfoField(&record); err == nil {
+			if ts := f.Interface().(RecordInfo).IngestTimestamp; ts != 0 {
+				date = time.UnixMilli(ts)
+			}
+		}
+		return "dt=" + date.UTC().Format(time.DateOnly)
+	}
+}
+
+// AppendToLocalParquet appends records to the local Parquet file at filePath, creating it if
+// it does not exist. Parquet files cannot be appended to once finalized, so this is a
+// read-modify-write: the existing records are read into memory, combined with the new ones,
+// and written through LocalSink, which only replaces the original once the write succeeds.
+func AppendToLocalParquet[T any](filePath string, records []T, config ParquetWriterConfig) error {
+	combined := CreateDataFrame(records)
+	if _, err := os.Stat(filePath); err == nil {
+		existing, err := ReadFromLocalParquet[T](filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read existing parquet file '%s': %w", filePath, err)
+		}
+		combined = Concat(existing, combined)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to stat parquet file '%s': %w", filePath, err)
+	}
+
+	return combined.Write(context.Background(), LocalSink{Path: filePath}, config)
+}
+
+// ErrNoRecordInfoField is returned by ParseFromJson when T has no settable RecordInfo field
+var ErrNoRecordInfoField = errors.New("does not have a settable RecordInfo field")
+
+// ErrMissingRequiredFields is returned by ParseFromJson when a record lacks any of the
+// parser's RequiredFields
+var ErrMissingRequiredFields = errors.New("missing required fields")
+
+// FieldDecodeError reports a JSON value that could not be decoded into its Go field
+type FieldDecodeError struct {
+	Field string       // Dotted path of the field, e.g. "address.zip"
+	Value string       // JSON value kind, e.g. "string"
+	Type  reflect.Type // Go type of the field
+	Err   error        // Underlying *json.UnmarshalTypeError
+}
+
+func (e *FieldDecodeError) Error() string {
+	return fmt.Sprintf("field %q: cannot unmarshal %s into %s", e.Field, e.Value, e.Type)
+}
+
+func (e *FieldDecodeError) Unwrap() error {
+	return e.Err
+}
+
+type BaseSchemaParser[T any] struct {
+	// Hasher computes RecordInfo.RowHash from the raw record bytes.
+	// When nil, the SHA-256 hex digest is used.
+	Hasher func([]byte) string
+	// RequiredFields lists top-level JSON keys that must be present in each record.
+	// Records missing any of them are rejected instead of being zero-filled.
+	RequiredFields []string
+	// Now supplies RecordInfo.IngestTimestamp. When nil, time.Now is used.
+	Now func() time.Time
+	// Transform is called on each decoded record before RecordInfo is set, e.g. to trim
+	// or normalize values. RowHash and RawData still reflect the raw record bytes.
+	// A non-nil error rejects the record.
+	Transform func(*T) error
+}
+
+// checkRequiredFields returns an error naming every required key absent from rawData
+func (p *BaseSchemaParser[T]) checkRequiredFields(rawData []byte) error {
+	if len(p.RequiredFields) == 0 {
+		return nil
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(rawData, &keys); err != nil {
+		return fmt.Errorf("failed to parse record keys: %w", err)
+	}
+
+	var missing []string
+	for _, field := range p.RequiredFields {
+		if _, ok := keys[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingRequiredFields, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// sha256Hex returns the hex-encoded SHA-256 digest of data
+func sha256Hex(data []byte) string {
+	h := sha256.New()
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (p *BaseSchemaParser[T]) ParseFromJson(
+	rawData []byte,
+	sourceInfo string,
+) (T, error) {
+	record, err := p.ParseFromJsonPlain(rawData)
+	if err != nil {
+		return record, err
+	}
+
+	// Calculate hash
+	hasher := p.Hasher
+	if hasher == nil {
+		hasher = sha256Hex
+	}
+	now := p.Now
+	if now == nil {
+		now = time.Now
+	}
+	recordInfo := RecordInfo{
+		RawData:         string(rawData),
+		SourceInfo:      sourceInfo,
+		IngestTimestamp: int64(now().UTC().UnixMilli()),
+		RowHash:         hasher(rawData),
+	}
+
+	// Use reflection to set the RecordInfo field if it exists
+	f, err := recordInfoField(&record)
+	if err != nil {
+		return record, err
+	}
+	f.Set(reflect.ValueOf(recordInfo))
+
+	return record, nil
+}
+
+// recordInfoField returns the settable RecordInfo field of *record
+func recordInfoField[T any](record *T) (reflect.Value, error) {
+	v := reflect.ValueOf(record).Elem()
+	if v.Kind() == reflect.Struct {
+		if f := v.FieldByName("RecordInfo"); f.IsValid() && f.CanSet() && f.Type() == reflect.TypeOf(RecordInfo{}) {
+			return f, nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("%w: type %T", ErrNoRecordInfoField, *record)
+}
+
+// EnrichRecordInfo populates RecordInfo for records built outside BaseSchemaParser. SourceInfo
+// and IngestTimestamp are set on every record, and RowHash is the SHA-256 hex digest of the
+// record's JSON encoding with RecordInfo cleared. RawData is left untouched.
+func EnrichRecordInfo[T any](df *DataFrame[T], sourceInfo string) error {
+	ingestTimestamp := int64(time.Now().UTC().UnixMilli())
+	for i := range df.Records {
+		f, err := recordInfoField(&df.Records[i])
+		if err != nil {
+			return err
+		}
+
+		// Hash a copy without RecordInfo so the hash only depends on the record data
+		stripped := df.Records[i]
+		sf, _ := recordInfoField(&stripped)
+		sf.Set(reflect.Zero(sf.Type()))
+		data, err := json.Marshal(stripped)
+		if err != nil {
+			return fmt.Errorf("failed to marshal record at index %d: %w", i, err)
+		}
+
+		info := f.Interface().(RecordInfo)
+		info.SourceInfo = sourceInfo
+		info.IngestTimestamp = ingestTimestamp
+		info.RowHash = sha256Hex(data)
+		f.Set(reflect.ValueOf(info))
+	}
+
+	return nil
+}
+
+// ParseFromJsonPlain decodes rawData into T and applies RequiredFields validation and
+// Transform without setting RecordInfo, so it also works for types that have no RecordInfo field.
+func (p *BaseSchemaParser[T]) ParseFromJsonPlain(rawData []byte) (T, error) {
+	var record T
+
+	// Parse the record data
+	if err := json.Unmarshal(rawData, &record); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			err = &FieldDecodeError{Field: typeErr.Field, Value: typeErr.Value, Type: typeErr.Type, Err: err}
+		}
+		return record, fmt.Errorf("failed to parse record: %w", err)
+	}
+
+	// Reject records that omit required keys
+	if err := p.checkRequiredFields(rawData); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	if p.Transform != nil {
+		if err := p.Transform(&record); err != nil {
+			var zero T
+			return zero, fmt.Errorf("failed to transform record: %w", err)
+		}
+	}
+
+	return record, nil
+}
+
+// ParseFromJsonArray parses a JSON array of records (or a single top-level object) and
+// enriches each record with RecordInfo. Failing records are reported by their index.
+func (p *BaseSchemaParser[T]) ParseFromJsonArray(data []byte, sourceInfo string) ([]T, error) {
+	trimmed := bytes.TrimSpace(data)
+
+	// A top-level object is treated as a single record
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		record, err := p.ParseFromJson(trimmed, sourceInfo)
+		if err != nil {
+			return nil, fmt.Errorf("record at index 0: %w", err)
+		}
+		return []T{record}, nil
+	}
+
+	var rawRecords []json.RawMessage
+	if err := json.Unmarshal(trimmed, &rawRecords); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON array: %w", err)
+	}
+
+	records := make([]T, 0, len(rawRecords))
+	var errs []error
+	for i, raw := range rawRecords {
+		record, err := p.ParseFromJson(raw, sourceInfo)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("record at index %d: %w", i, err))
+			continue
+		}
+		records = append(records, record)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return records, nil
+}
+
+// S3Config holds AWS S3 configuration
+type S3Config struct {
+	Region          string
+	Bucket          string
+	Key             string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Endpoint        string // Optional for custom endpoints
+}
+
+// S3WriteOptions holds object-level settings for S3 writes
+type S3WriteOptions struct {
+	ACL         string // Canned ACL for the object, defaults to "private"
+	ContentType string // Content-Type of the object, defaults to ParquetContentType
+	// PartSize is the multipart upload part size in bytes. Objects larger than one part are
+	// uploaded in parts, so it also bounds memory use per part. S3 requires at least 5MB
+	// (s3manager.MinUploadPartSize) and allows at most 10,000 parts per object, so raise it
+	// for very large exports. Zero uses the s3manager default of 5MB.
+	PartSize int64
+}
+
+// S3RetryOptions controls how S3 requests are retried on transient failures
+type S3RetryOptions struct {
+	MaxRetries int           // Maximum retries per request, zero disables retries
+	BaseDelay  time.Duration // Delay before the first retry, doubled with jitter on each attempt
+}
+
+// WithS3Retry returns a copy of s3client whose requests are retried according to opts.
+// Retries apply to individual S3 requests (uploads, ranged reads, HEADs), so records are
+// serialized once and only the failed network operation is repeated.
+func WithS3Retry(s3client *awsS3.S3, opts S3RetryOptions) *awsS3.S3 {
+	c := *s3client.Client
+	c.Retryer = awsClient.DefaultRetryer{
+		NumMaxRetries:    opts.MaxRetries,
+		MinRetryDelay:    opts.BaseDelay,
+		MinThrottleDelay: opts.BaseDelay,
+	}
+	c.Config.MaxRetries = aws.Int(opts.MaxRetries)
+
+	return &awsS3.S3{Client: &c}
+}
+
+// ParquetContentType is the media type used for Parquet objects written to S3
+const ParquetContentType = "application/vnd.apache.parquet"
+
+// JSONLContentType is the media type used for JSONL objects written to S3
+const JSONLContentType = "application/x-ndjson"
+
+// withContentType returns an uploader option that sets the Content-Type of the uploaded object
+func withContentType(contentType string) func(*s3manager.Uploader) {
+	return func(u *s3manager.Uploader) {
+		u.RequestOptions = append(u.RequestOptions, func(r *request.Request) {
+			switch input := r.Params.(type) {
+			case *awsS3.PutObjectInput:
+				input.ContentType = aws.String(contentType)
+			case *awsS3.CreateMultipartUploadInput:
+				input.ContentType = aws.String(contentType)
+			}
+		})
+	}
+}
+
+// withPartSize returns an uploader option that sets the multipart upload part size
+func withPartSize(partSize int64) func(*s3manager.Uploader) {
+	return func(u *s3manager.Uploader) {
+		u.PartSize = partSize
+	}
+}
+
+// WriteToS3Parquet writes the DataFrame to an S3 Parquet file
+func (df *DataFrame[T]) WriteToS3Parquet(ctx context.Context, s3client *awsS3.S3, bucket, key string, config ...ParquetWriterConfig) error {
+	return df.WriteToS3ParquetWithOptions(ctx, s3client, bucket, key, S3WriteOptions{}, config...)
+}
+
+// WriteToS3ParquetWithOptions writes the DataFrame to an S3 Parquet file using the given options.
+// Output larger than one part is sent as a multipart upload, which is aborted if the write fails.
+func (df *DataFrame[T]) WriteToS3ParquetWithOptions(ctx context.Context, s3client *awsS3.S3, bucket, key string, opts S3WriteOptions, config ...ParquetWriterConfig) error {
+	// Use provided config or default
+	cfg := DefaultParquetConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	sink := S3Sink{Client: s3client, Bucket: bucket, Key: key, Options: opts}
+	if err := df.Write(ctx, sink, cfg); err != nil {
+		return fmt.Errorf("failed to write parquet file to bucket '%s' key '%s': %w", bucket, key, err)
+	}
+
+	return nil
+}
+
+// WriteToS3JSONL streams the DataFrame as JSONL to an S3 object without staging it locally.
+// Records are encoded into a pipe that feeds the upload, so large output is sent as a
+// multipart upload, which is aborted if encoding or the upload fails.
+func (df *DataFrame[T]) WriteToS3JSONL(ctx context.Context, s3client *awsS3.S3, bucket, key string) error {
+	pr, pw := io.Pipe()
+	encodeErr := make(chan error, 1)
+	go func() {
+		err := WriteJSONLToWriter(pw, df.Records)
+		pw.CloseWithError(err)
+		encodeErr <- err
+	}()
+
+	uploader := s3manager.NewUploaderWithClient(s3client)
+	_, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        pr,
+		ContentType: aws.String(JSONLContentType),
+	})
+	// Unblock the encoder if the upload stopped reading early. An encoding failure
+	// reaches the upload as a read error, so it is reported through err.
+	pr.CloseWithError(err)
+	encErr := <-encodeErr
+	if err != nil {
+		return fmt.Errorf("failed to upload JSONL to bucket '%s' key '%s': %w", bucket, key, err)
+	}
+	if encErr != nil {
+		return fmt.Errorf("failed to encode JSONL for bucket '%s' key '%s': %w", bucket, key, encErr)
+	}
+
+	return nil
+}
+
+// newParquetReaderFor creates a parquet reader for T, along with the codec that converts its
+// time.Time fields if it has any
+func newParquetReaderFor[T any](file source.ParquetFile) (*reader.ParquetReader, *timeCodec, error) {
+	// Create an empty instance for schema reference
+	var empty T
+	var schema interface{} = &empty
+
+	codec, err := timeCodecFor(reflect.TypeOf(empty))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to infer parquet schema for type %T: %w", empty, err)
+	}
+	if codec != nil {
+		schema = reflect.New(codec.storageType).Interface()
+	}
+
+	// Create parquet reader
+	pr, err := reader.NewParquetReader(file, schema, 4) // Default concurrency of 4
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create parquet reader: %w", err)
+	}
+	return pr, codec, nil
+}
+
+// readParquetRows reads the next n rows of pr into a new slice, converting them with codec
+// when it is non-nil
+func readParquetRows[T any](pr *reader.ParquetReader, codec *timeCodec, n int) ([]T, error) {
+	records := make([]T, n)
+	if codec == nil {
+		err := pr.Read(&records)
+		return records, err
+	}
+
+	rows := reflect.New(reflect.SliceOf(codec.storageType))
+	rows.Elem().Set(reflect.MakeSlice(rows.Elem().Type(), n, n))
+	if err := pr.Read(rows.Interface()); err != nil {
+		return nil, err
+	}
+	for i := range records {
+		records[i] = codec.fromStorage(rows.Elem().Index(i)).Interface().(T)
+	}
+	return records, nil
+}
+
+// ReadFromParquet reads a DataFrame from a Parquet file. Null values of OPTIONAL columns
+// are read into pointer fields as nil, and top-level time.Time fields tagged as INT64
+// TIMESTAMP columns are read as UTC times.
+func ReadFromParquet[T any](file source.ParquetFile) (*DataFrame[T], error) {
+	pr, codec, err := newParquetReaderFor[T](file)
+	if err != nil {
+		return nil, err
+	}
+	defer pr.ReadStop()
+
+	// Read the data
+	records, err := readParquetRows[T](pr, codec, int(pr.GetNumRows()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parquet data: %w", err)
+	}
+
+	// Create and return the DataFrame
+	return CreateDataFrame(records), nil
+}
+
+// parquetContextBatchSize is the number of rows ReadFromParquetContext reads between
+// checks of its context
+const parquetContextBatchSize = 10000
+
+// ReadFromParquetContext reads a DataFrame from a Parquet file like ReadFromParquet, but in
+// batches, checking ctx between them. When ctx is cancelled the read stops and ctx.Err() is
+// returned, so a large read can be abandoned, e.g. when an HTTP client disconnects.
+func ReadFromParquetContext[T any](ctx context.Context, file source.ParquetFile) (*DataFrame[T], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	records := []T{}
+	err := ReadParquetBatches(file, parquetContextBatchSize, func(batch []T) error {
+		records = append(records, batch...)
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateDataFrame(records), nil
+}
+
+// ReadParquetBatches reads a Parquet file batchSize rows at a time and calls fn with each
+// batch, so only one batch is held in memory. The last batch may be shorter. Reading stops
+// at the first error from fn, which is returned unchanged. Each batch is a new slice, so fn
+// may keep it.
+func ReadParquetBatches[T any](file source.ParquetFile, batchSize int, fn func([]T) error) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	pr, codec, err := newParquetReaderFor[T](file)
+	if err != nil {
+		return err
+	}
+	defer pr.ReadStop()
+
+	for read, numRows := 0, int(pr.GetNumRows()); read < numRows; {
+		n := min(batchSize, numRows-read)
+		batch, err := readParquetRows[T](pr, codec, n)
+		if err != nil {
+			return fmt.Errorf("failed to read parquet rows %d-%d: %w", read, read+n-1, err)
+		}
+		read += len(batch)
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParquetMetadata describes a Parquet file as recorded in its footer
+type ParquetMetadata struct {
+	NumRows      int64
+	NumRowGroups int
+	SchemaName   string   // Name of the root schema element
+	Columns      []string // Top-level column names as stored in the file schema
+	CreatedBy    string
+	// ColumnEncodings lists the encodings recorded for each leaf column across all row groups,
+	// keyed by dotted path in the file schema (e.g. "_recordinfo._raw_data"). Dictionary
+	// encoding shows up as PLAIN_DICTIONARY; other value encodings are recorded as PLAIN.
+	ColumnEncodings map[string][]parquet.Encoding
+	// ColumnStatistics holds the statistics of each leaf column chunk, one entry per row group
+	// in file order, keyed like ColumnEncodings. MinValue and MaxValue are PLAIN-encoded (e.g.
+	// little-endian for INT32) and are nil when the file was written with OmitStats.
+	ColumnStatistics map[string][]*parquet.Statistics
+}
+
+// ReadParquetMetadata reads the footer of a Parquet file without reading any row data
+func ReadParquetMetadata(file source.ParquetFile) (ParquetMetadata, error) {
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		return ParquetMetadata{}, fmt.Errorf("failed to read parquet footer: %w", err)
+	}
+
+	schemas := pr.SchemaHandler.SchemaElements
+	// subtreeSize counts a schema element together with all of its descendants
+	var subtreeSize func(i int) int
+	subtreeSize = func(i int) int {
+		size := 1
+		for c := int32(0); c < schemas[i].GetNumChildren(); c++ {
+			size += subtreeSize(i + size)
+		}
+		return size
+	}
+
+	// Walk the direct children of the root element
+	var columns []string
+	for i := 1; i < len(schemas); i += subtreeSize(i) {
+		columns = append(columns, pr.SchemaHandler.GetExName(i))
+	}
+
+	// Chunks are matched to leaves by position since the writer stores its own internal
+	// field names in each chunk's path
+	leaves := leafColumns(pr.SchemaHandler)
+	encodings := make(map[string][]parquet.Encoding)
+	statistics := make(map[string][]*parquet.Statistics)
+	for _, rowGroup := range pr.Footer.GetRowGroups() {
+		for j, chunk := range rowGroup.GetColumns() {
+			if j >= len(leaves) {
+				break
+			}
+			path := leaves[j].path
+			statistics[path] = append(statistics[path], chunk.GetMetaData().GetStatistics())
+			for _, encoding := range chunk.GetMetaData().GetEncodings() {
+				if !slices.Contains(encodings[path], encoding) {
+					encodings[path] = append(encodings[path], encoding)
+				}
+			}
+		}
+	}
+
+	return ParquetMetadata{
+		NumRows:          pr.GetNumRows(),
+		NumRowGroups:     len(pr.Footer.GetRowGroups()),
+		SchemaName:       pr.SchemaHandler.GetExName(0),
+		Columns:          columns,
+		CreatedBy:        pr.Footer.GetCreatedBy(),
+		ColumnEncodings:  encodings,
+		ColumnStatistics: statistics,
+	}, nil
+}
+
+// ReadLocalParquetMetadata reads the footer metadata of a local Parquet file
+func ReadLocalParquetMetadata(filePath string) (ParquetMetadata, error) {
+	fr, err := local.NewLocalFileReader(filePath)
+	if err != nil {
+		return ParquetMetadata{}, fmt.Errorf("failed to open parquet file '%s': %w", filePath, err)
+	}
+	defer fr.Close()
+
+	return ReadParquetMetadata(fr)
+}
+
+// ReadFromParquetColumns reads a DataFrame from a Parquet file, only reading the given top-level columns.
+// Column names refer to the names stored in the file (the parquet tag name). Fields of T that are not
+// listed are left at their zero value. time.Time fields are read as in ReadFromParquet.
+func ReadFromParquetColumns[T any](file source.ParquetFile, columns []string) (*DataFrame[T], error) {
+	// Validate the requested columns against the file schema
+	metadata, err := ReadParquetMetadata(file)
+	if err != nil {
+		return nil, err
+	}
+	available := metadata.Columns
+	availableSet := make(map[string]bool, len(available))
+	for _, name := range available {
+		availableSet[name] = true
+	}
+	requested := make(map[string]bool, len(columns))
+	for _, name := range columns {
+		if !availableSet[name] {
+			return nil, fmt.Errorf("column '%s' not found in parquet schema (available: %s)",
+				name, strings.Join(available, ", "))
+		}
+		requested[name] = true
+	}
+
+	pr, codec, err := newParquetReaderFor[T](file)
+	if err != nil {
+		return nil, err
+	}
+	defer pr.ReadStop()
+
+	// Drop the column buffers that were not requested so they are never read
+	for inPath, cb := range pr.ColumnBuffers {
+		exPath := common.StrToPath(pr.SchemaHandler.InPathToExPath[inPath])
+		if len(exPath) > 1 && requested[exPath[1]] {
+			continue
+		}
+		if cb != nil {
+			cb.PFile.Close()
+		}
+		delete(pr.ColumnBuffers, inPath)
+	}
+
+	records, err := readParquetRows[T](pr, codec, int(pr.GetNumRows()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parquet data: %w", err)
+	}
+
+	return CreateDataFrame(records), nil
+}
+
+// ReadFromLocalParquetColumns reads the given columns of a local Parquet file into a DataFrame
+func ReadFromLocalParquetColumns[T any](filePath string, columns []string) (*DataFrame[T], error) {
+	fr, err := local.NewLocalFileReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open parquet file '%s': %w", filePath, err)
+	}
+	defer fr.Close()
+
+	return ReadFromParquetColumns[T](fr, columns)
+}
+
+// ReadFromS3ParquetColumns reads the given columns of an S3 Parquet file into a DataFrame
+func ReadFromS3ParquetColumns[T any](ctx context.Context, s3client *awsS3.S3, bucket, key string, columns []string) (*DataFrame[T], error) {
+	fr, err := s3.NewS3FileReaderWithClient(ctx, s3client, bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open S3 parquet file at bucket '%s' key '%s': %w",
+			bucket, key, err)
+	}
+	defer fr.Close()
+
+	return ReadFromParquetColumns[T](fr, columns)
+}
+
+// ReadFromLocalParquet reads a DataFrame from a local Parquet file
+func ReadFromLocalParquet[T any](filePath string) (*DataFrame[T], error) {
+	fr, err := local.NewLocalFileReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open parquet file '%s': %w", filePath, err)
+	}
+	defer fr.Close()
+
+	return ReadFromParquet[T](fr)
+}
+
+// ReadFromLocalParquetDir reads every *.parquet file under dir (recursively) and concatenates
+// their records in sorted path order. Read failures are collected and reported per file.
+func ReadFromLocalParquetDir[T any](dir string) (*DataFrame[T], error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".parquet" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list parquet files in '%s': %w", dir, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no parquet files found in '%s'", dir)
+	}
+	sort.Strings(paths)
+
+	dfs := make([]*DataFrame[T], 0, len(paths))
+	var errs []error
+	for _, path := range paths {
+		df, err := ReadFromLocalParquet[T](path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read '%s': %w", path, err))
+			continue
+		}
+		dfs = append(dfs, df)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return Concat(dfs...), nil
+}
+
+// ReadFromParquetBytes reads a DataFrame from Parquet file contents held in memory
+func ReadFromParquetBytes[T any](data []byte) (*DataFrame[T], error) {
+	return ReadFromParquet[T](buffer.NewBufferFileFromBytes(data))
+}
+
+// ReadFromS3Parquet reads a DataFrame from an S3 Parquet file
+func ReadFromS3Parquet[T any](ctx context.Context, s3client *awsS3.S3, bucket, key string) (*DataFrame[T], error) {
+	fr, err := s3.NewS3FileReaderWithClient(ctx, s3client, bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open S3 parquet file at bucket '%s' key '%s': %w",
+			bucket, key, err)
+	}
+	defer fr.Close()
+
+	return ReadFromParquet[T](fr)
+}
+
+// ReadFromS3ParquetPrefix reads every .parquet object under prefix and concatenates their
+// records in key order. Listings larger than one page are followed via continuation tokens.
+func ReadFromS3ParquetPrefix[T any](ctx context.Context, s3client *awsS3.S3, bucket, prefix string) (*DataFrame[T], error) {
+	var keys []string
+	input := &awsS3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	err := s3client.ListObjectsV2PagesWithContext(ctx, input, func(page *awsS3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			if key := aws.StringValue(obj.Key); strings.HasSuffix(key, ".parquet") {
+				keys = append(keys, key)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects in bucket '%s' prefix '%s': %w", bucket, prefix, err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no parquet objects found in bucket '%s' prefix '%s'", bucket, prefix)
+	}
+
+	dfs := make([]*DataFrame[T], 0, len(keys))
+	for _, key := range keys {
+		df, err := ReadFromS3Parquet[T](ctx, s3client, bucket, key)
+		if err != nil {
+			return nil, err
+		}
+		dfs = append(dfs, df)
+	}
+
+	return Concat(dfs...), nil
+}
+
+// JSONLOptions controls how WriteToJSONLWithOptions creates its output
+type JSONLOptions struct {
+	// DirPerm is the permission of parent directories created for the file, before the umask.
+	// Zero uses 0755.
+	DirPerm os.FileMode
+	// Append adds the records to the end of an existing file instead of truncating it, e.g.
+	// for log-style JSONL. The file is created if it does not exist.
+	Append bool
+}
+
+// WriteToJSONL writes the DataFrame to a JSONL file
+func (df *DataFrame[T]) WriteToJSONL(filePath string) error {
+	return df.WriteToJSONLWithOptions(filePath, JSONLOptions{})
+}
+
+// WriteToJSONLWithOptions writes the DataFrame to a JSONL file using the given options.
+// The zero JSONLOptions behaves like WriteToJSONL.
+func (df *DataFrame[T]) WriteToJSONLWithOptions(filePath string, opts JSONLOptions) error {
+	dirPerm := opts.DirPerm
+	if dirPerm == 0 {
+		dirPerm = 0755
+	}
+
+	// Create parent directories if they don't exist
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", dir, err)
+	}
+
+	// Create or truncate the output file, or append to it
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if opts.Append {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	file, err := os.OpenFile(filePath, flag, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to create JSONL file '%s': %w", filePath, err)
+	}
+	defer file.Close()
+
+	return WriteJSONLToWriter(file, df.Records)
+}
+
+// WriteToJSONLSlim writes the DataFrame to a JSONL file without the RecordInfo metadata,
+// see WithoutRecordInfo
+func (df *DataFrame[T]) WriteToJSONLSlim(filePath string) error {
+	slim, err := df.WithoutRecordInfo()
+	if err != nil {
+		return err
+	}
+	return slim.WriteToJSONL(filePath)
+}
+
+// WriteToJSON writes the DataFrame to a file as a single indented JSON array for human review.
+// Use WriteToJSONArray for compact output or WriteToJSONL for newline-delimited records.
+func (df *DataFrame[T]) WriteToJSON(filePath string) error {
+	// Create parent directories if they don't exist
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", dir, err)
+	}
+
+	// Create or truncate the output file
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create JSON file '%s': %w", filePath, err)
+	}
+	defer file.Close()
+
+	// Encode an empty frame as [] rather than null
+	records := df.Records
+	if records == nil {
+		records = []T{}
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return fmt.Errorf("failed to encode records to JSON file '%s': %w", filePath, err)
+	}
+
+	return nil
+}
+
+// WriteJSONLToWriter writes records as JSONL to w, e.g. an HTTP response or pipe.
+// Output is buffered and flushed before returning.
+func WriteJSONLToWriter[T any](w io.Writer, records []T) error {
+	// Create a buffered writer for better performance
+	writer := bufio.NewWriter(w)
+
+	// Process each record
+	for i, record := range records {
+		// Marshal the record to JSON
+		jsonBytes, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal record at index %d: %w", i, err)
+		}
+
+		// Write the JSON line with a newline character
+		if _, err := writer.Write(jsonBytes); err != nil {
+			return fmt.Errorf("failed to write record at index %d: %w", i, err)
+		}
+		if _, err := writer.Write([]byte("\n")); err != nil {
+			return fmt.Errorf("failed to write newline at index %d: %w", i, err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush JSONL output: %w", err)
+	}
+
+	return nil
+}
+
+// WriteToJSONArray writes the DataFrame to a file as a single JSON array. Records are
+// streamed one at a time, so the whole array is never held in memory.
+func (df *DataFrame[T]) WriteToJSONArray(filePath string) error {
+	// Create parent directories if they don't exist
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", dir, err)
+	}
+
+	// Create or truncate the output file
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create JSON file '%s': %w", filePath, err)
+	}
+	defer file.Close()
+
+	// Create a buffered writer for better performance
+	writer := bufio.NewWriter(file)
+
+	if err := writer.WriteByte('['); err != nil {
+		return fmt.Errorf("failed to write array start: %w", err)
+	}
+	for i, record := range df.Records {
+		jsonBytes, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal record at index %d: %w", i, err)
+		}
+
+		// Separate records with a comma
+		if i > 0 {
+			if err := writer.WriteByte(','); err != nil {
+				return fmt.Errorf("failed to write separator at index %d: %w", i, err)
+			}
+		}
+		if _, err := writer.Write(jsonBytes); err != nil {
+			return fmt.Errorf("failed to write record at index %d: %w", i, err)
+		}
+	}
+	if err := writer.WriteByte(']'); err != nil {
+		return fmt.Errorf("failed to write array end: %w", err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush JSON file '%s': %w", filePath, err)
+	}
+
+	return nil
+}
+
+// SelectToJSONL writes only the named JSON fields of each record to a JSONL file.
+// Field names refer to the keys of the record's JSON representation.
+func (df *DataFrame[T]) SelectToJSONL(filePath string, fields ...string) error {
+	selected := make([]map[string]json.RawMessage, len(df.Records))
+	for i, record := range df.Records {
+		projected, err := selectJSONFields(record, i, fields)
+		if err != nil {
+			return err
+		}
+		selected[i] = projected
+	}
+
+	return CreateDataFrame(selected).WriteToJSONL(filePath)
+}
+
+// WriteToJSONLRenamed writes the DataFrame to a JSONL file with top-level JSON keys renamed
+// according to renames (old key to new key). Every old key must be present in each record
+// and no new key may collide with another key of the record.
+func (df *DataFrame[T]) WriteToJSONLRenamed(filePath string, renames map[string]string) error {
+	renamed := make([]map[string]json.RawMessage, len(df.Records))
+	for i, record := range df.Records {
+		jsonBytes, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal record at index %d: %w", i, err)
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+			return fmt.Errorf("record at index %d is not a JSON object: %w", i, err)
+		}
+
+		out := make(map[string]json.RawMessage, len(fields))
+		for key, value := range fields {
+			if newKey, ok := renames[key]; ok {
+				key = newKey
+			}
+			if _, ok := out[key]; ok {
+				return fmt.Errorf("renamed field '%s' collides with another field in record at index %d", key, i)
+			}
+			out[key] = value
+		}
+		for oldKey := range renames {
+			if _, ok := fields[oldKey]; !ok {
+				return fmt.Errorf("field '%s' not present in record at index %d", oldKey, i)
+			}
+		}
+		renamed[i] = out
+	}
+
+	return CreateDataFrame(renamed).WriteToJSONL(filePath)
+}
+
+// selectJSONFields returns the named fields of record's JSON representation. index is only
+// used in error messages.
+func selectJSONFields(record interface{}, index int, fields []string) (map[string]json.RawMessage, error) {
+	jsonBytes, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal record at index %d: %w", index, err)
+	}
+
+	var full map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBytes, &full); err != nil {
+		return nil, fmt.Errorf("record at index %d is not a JSON object: %w", index, err)
+	}
+
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		value, ok := full[field]
+		if !ok {
+			return nil, fmt.Errorf("field '%s' not present in record at index %d", field, index)
+		}
+		projected[field] = value
+	}
+	return projected, nil
+}
+
+// ReadFromJSONL reads a DataFrame from a JSONL file
+func ReadFromJSONL[T any](filePath string) (*DataFrame[T], error) {
+	// Open the file
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JSONL file '%s': %w", filePath, err)
+	}
+	defer file.Close()
+
+	return ReadJSONLFromReader[T](file)
+}
+
+// ReadFromS3JSONL reads a DataFrame from a JSONL object in S3, streaming the body into the
+// parser. Keys ending in ".gz" are decompressed transparently.
+func ReadFromS3JSONL[T any](ctx context.Context, s3client *awsS3.S3, bucket, key string) (*DataFrame[T], error) {
+	obj, err := s3client.GetObjectWithContext(ctx, &awsS3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get JSONL object at bucket '%s' key '%s': %w", bucket, key, err)
+	}
+	defer obj.Body.Close()
+
+	var body io.Reader = obj.Body
+	if strings.HasSuffix(key, ".gz") {
+		gz, err := gzip.NewReader(obj.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip stream at bucket '%s' key '%s': %w", bucket, key, err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	df, err := ReadJSONLFromReader[T](body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSONL from bucket '%s' key '%s': %w", bucket, key, err)
 	}
+	return df, nil
 }
 
-// WriteToParquet writes the DataFrame to a Parquet file using the provided writer
-func (df *DataFrame[T]) WriteToParquet(fw source.ParquetFile, config ParquetWriterConfig) error {
-	// Create the parquet writer
-	pw, err := writer.NewParquetWriter(fw, df.schema, config.Concurrency)
+// ConvertJSONLToParquet reads the JSONL file at jsonlPath into T and writes the records to a
+// local Parquet file. Empty input produces a valid Parquet file with no rows, and malformed
+// lines fail with their line number.
+func ConvertJSONLToParquet[T any](jsonlPath, parquetPath string, config ParquetWriterConfig) error {
+	df, err := ReadFromJSONL[T](jsonlPath)
 	if err != nil {
-		return fmt.Errorf("failed to create parquet writer: %w", err)
+		return err
 	}
 
-	// Set compression
-	pw.CompressionType = config.Compression
+	return df.WriteToLocalParquet(parquetPath, config)
+}
 
-	// Write each record
-	for i, record := range df.Records {
-		if err := pw.Write(record); err != nil {
-			_ = pw.WriteStop()
-			return fmt.Errorf("failed to write record at index %d: %w", i, err)
+// ReadJSONLFromReader reads a DataFrame from JSONL data, e.g. an HTTP body or gzip stream.
+// Empty lines are skipped and each line may be up to 10MB.
+func ReadJSONLFromReader[T any](r io.Reader) (*DataFrame[T], error) {
+	// Create a scanner to read line by line
+	scanner := bufio.NewScanner(r)
+
+	// For large JSON objects, increase the buffer size if needed
+	const maxCapacity = 10 * 1024 * 1024 // 10MB
+	buf := make([]byte, 0, 64*1024)      // Start with 64KB
+	scanner.Buffer(buf, maxCapacity)
+
+	// Parse each line into a record
+	var records []T
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+
+		// Skip empty lines
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		// Parse the JSON into a record
+		var record T
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("failed to parse JSONL at line %d: %w", lineNum, err)
 		}
+
+		records = append(records, record)
 	}
 
-	// Finalize writing
-	if err := pw.WriteStop(); err != nil {
-		return fmt.Errorf("failed to finalize parquet file: %w", err)
+	// Check for scanner errors
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading JSONL: %w", err)
 	}
 
-	return nil
+	// Create and return the DataFrame
+	return CreateDataFrame(records), nil
 }
 
-// WriteToLocalParquet writes the DataFrame to a local Parquet file
-func (df *DataFrame[T]) WriteToLocalParquet(filePath string, config ...ParquetWriterConfig) error {
-	fw, err := local.NewLocalFileWriter(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create local writer for path '%s': %w", filePath, err)
-	}
-	defer fw.Close()
+// fieldColumn maps a column name, such as a CSV header, to the struct field it is read from
+// and written to
+type fieldColumn struct {
+	Name  string
+	Index []int
+}
 
-	// Use provided config or default
-	cfg := DefaultParquetConfig()
-	if len(config) > 0 {
-		cfg = config[0]
+// Columns returns the column names of T in field order: the json tag name of each exported
+// field, or the field name when it has none. Embedded structs such as RecordInfo are
+// flattened, so their fields (e.g. "_raw_data") appear in place of the embedded field.
+// Fields tagged json:"-" are skipped, and types other than structs have no columns.
+func Columns[T any]() []string {
+	var empty T
+	t := reflect.TypeOf(empty)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
 	}
 
-	return df.WriteToParquet(fw, cfg)
+	var names []string
+	for _, col := range fieldColumnsFor(t) {
+		names = append(names, col.Name)
+	}
+	return names
 }
 
-type BaseSchemaParser[T any] struct{}
-
-func (p *BaseSchemaParser[T]) ParseFromJson(
-	rawData []byte,
-	sourceInfo string,
-) (T, error) {
-	var record T
+// fieldColumnsFor derives the columns for a struct type using json tags (or field names).
+// Embedded structs are flattened so that their fields become top-level columns.
+func fieldColumnsFor(t reflect.Type) []fieldColumn {
+	var columns []fieldColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for _, col := range fieldColumnsFor(field.Type) {
+				col.Index = append([]int{i}, col.Index...)
+				columns = append(columns, col)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
 
-	// Parse the record data
-	if err := json.Unmarshal(rawData, &record); err != nil {
-		return record, fmt.Errorf("failed to parse record: %w", err)
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		columns = append(columns, fieldColumn{Name: name, Index: []int{i}})
 	}
+	return columns
+}
 
-	// Calculate hash
-	h := sha256.New()
-	h.Write(rawData)
-	recordInfo := RecordInfo{
-		RawData:         string(rawData),
-		SourceInfo:      sourceInfo,
-		IngestTimestamp: int64(time.Now().UTC().UnixMilli()),
-		RowHash:         hex.EncodeToString(h.Sum(nil)),
+// formatCSVValue converts a scalar field value to its CSV string form
+func formatCSVValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
 	}
 
-	// Use reflection to set the RecordInfo field if it exists
-	v := reflect.ValueOf(&record).Elem()
-	f := v.FieldByName("RecordInfo")
-	if f.IsValid() && f.CanSet() {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported CSV field kind %s", v.Kind())
+	}
+}
 
-		f.Set(reflect.ValueOf(recordInfo))
-	} else {
-		return record, fmt.Errorf("type %T does not have a settable RecordInfo field", record)
+// parseCSVValue parses a CSV string into the given settable field value
+func parseCSVValue(s string, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if s == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		ptr := reflect.New(v.Type().Elem())
+		if err := parseCSVValue(s, ptr.Elem()); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
 	}
 
-	return record, nil
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported CSV field kind %s", v.Kind())
+	}
+	return nil
 }
 
-// S3Config holds AWS S3 configuration
-type S3Config struct {
-	Region          string
-	Bucket          string
-	Key             string
-	AccessKeyID     string
-	SecretAccessKey string
-	SessionToken    string
-	Endpoint        string // Optional for custom endpoints
-}
+// WriteToCSV writes the DataFrame to a CSV file with a header row derived from T
+func (df *DataFrame[T]) WriteToCSV(filePath string) error {
+	var empty T
+	t := reflect.TypeOf(empty)
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("CSV output requires a struct type, got %T", empty)
+	}
+	columns := fieldColumnsFor(t)
 
-// WriteToS3Parquet writes the DataFrame to an S3 Parquet file
-func (df *DataFrame[T]) WriteToS3Parquet(ctx context.Context, s3client *awsS3.S3, bucket, key string, config ...ParquetWriterConfig) error {
-	// Create S3 file writer with custom client
-	fw, err := s3.NewS3FileWriterWithClient(ctx, s3client, bucket, key, "private", nil)
+	// Create parent directories if they don't exist
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", dir, err)
+	}
+
+	file, err := os.Create(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to create S3 writer for bucket '%s' and key '%s': %w",
-			bucket, key, err)
+		return fmt.Errorf("failed to create CSV file '%s': %w", filePath, err)
 	}
-	defer fw.Close()
+	defer file.Close()
 
-	// Use provided config or default
-	cfg := DefaultParquetConfig()
-	if len(config) > 0 {
-		cfg = config[0]
+	w := csv.NewWriter(file)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	if err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	row := make([]string, len(columns))
+	for i, record := range df.Records {
+		v := reflect.ValueOf(record)
+		for j, col := range columns {
+			value, err := formatCSVValue(v.FieldByIndex(col.Index))
+			if err != nil {
+				return fmt.Errorf("failed to format column '%s' at index %d: %w", col.Name, i, err)
+			}
+			row[j] = value
+		}
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("failed to write record at index %d: %w", i, err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV file '%s': %w", filePath, err)
 	}
 
-	return df.WriteToParquet(fw, cfg)
+	return nil
 }
 
-// ReadFromParquet reads a DataFrame from a Parquet file
-func ReadFromParquet[T any](file source.ParquetFile) (*DataFrame[T], error) {
-	// Create an empty instance for schema reference
+// ReadFromCSV reads a DataFrame from a CSV file, mapping header columns to struct fields by name
+func ReadFromCSV[T any](filePath string) (*DataFrame[T], error) {
 	var empty T
-	schema := &empty
+	t := reflect.TypeOf(empty)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("CSV input requires a struct type, got %T", empty)
+	}
+	columns := fieldColumnsFor(t)
 
-	// Create parquet reader
-	pr, err := reader.NewParquetReader(file, schema, 4) // Default concurrency of 4
+	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create parquet reader: %w", err)
+		return nil, fmt.Errorf("failed to open CSV file '%s': %w", filePath, err)
 	}
-	defer pr.ReadStop()
+	defer file.Close()
 
-	// Get the number of rows
-	numRows := int(pr.GetNumRows())
+	r := csv.NewReader(file)
 
-	// Prepare the slice to hold all records
-	records := make([]T, numRows)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header from '%s': %w", filePath, err)
+	}
 
-	// Read the data
-	if err := pr.Read(&records); err != nil {
-		return nil, fmt.Errorf("failed to read parquet data: %w", err)
+	// Every column of T must be present in the header
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		positions[name] = i
+	}
+	for _, col := range columns {
+		if _, ok := positions[col.Name]; !ok {
+			return nil, fmt.Errorf("CSV file '%s' is missing required column '%s'", filePath, col.Name)
+		}
+	}
+
+	records := make([]T, 0)
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// csv.ParseError already reports the line number
+			return nil, fmt.Errorf("failed to read CSV from '%s': %w", filePath, err)
+		}
+
+		var record T
+		v := reflect.ValueOf(&record).Elem()
+		for _, col := range columns {
+			if err := parseCSVValue(row[positions[col.Name]], v.FieldByIndex(col.Index)); err != nil {
+				// Quoted fields may span lines, so ask the reader where the field started
+				line, _ := r.FieldPos(positions[col.Name])
+				return nil, fmt.Errorf("failed to parse column '%s' at line %d: %w", col.Name, line, err)
+			}
+		}
+		records = append(records, record)
 	}
 
-	// Create and return the DataFrame
 	return CreateDataFrame(records), nil
 }
 
-// ReadFromLocalParquet reads a DataFrame from a local Parquet file
-func ReadFromLocalParquet[T any](filePath string) (*DataFrame[T], error) {
-	fr, err := local.NewLocalFileReader(filePath)
+// ConvertCSVToParquet reads the CSV file at csvPath into T, as ReadFromCSV does, and writes
+// the records to a local Parquet file. Parse errors report the CSV line number and leave
+// parquetPath untouched.
+func ConvertCSVToParquet[T any](csvPath, parquetPath string, config ParquetWriterConfig) error {
+	df, err := ReadFromCSV[T](csvPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open parquet file '%s': %w", filePath, err)
+		return err
 	}
-	defer fr.Close()
 
-	return ReadFromParquet[T](fr)
+	return df.WriteToLocalParquet(parquetPath, config)
 }
 
-// ReadFromS3Parquet reads a DataFrame from an S3 Parquet file
-func ReadFromS3Parquet[T any](ctx context.Context, s3client *awsS3.S3, bucket, key string) (*DataFrame[T], error) {
-	fr, err := s3.NewS3FileReaderWithClient(ctx, s3client, bucket, key)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open S3 parquet file at bucket '%s' key '%s': %w",
-			bucket, key, err)
+// avroAPI encodes and decodes Avro records using the struct json tags as field names
+var avroAPI = avro.Config{TagKey: "json"}.Freeze()
+
+// avroTypeFor maps a Go field type to its Avro type. Pointers become unions with null.
+func avroTypeFor(t reflect.Type) (interface{}, error) {
+	if t.Kind() == reflect.Ptr {
+		elem, err := avroTypeFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{"null", elem}, nil
 	}
-	defer fr.Close()
 
-	return ReadFromParquet[T](fr)
+	switch t.Kind() {
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return "int", nil
+	case reflect.Int, reflect.Int64:
+		return "long", nil
+	case reflect.Float32:
+		return "float", nil
+	case reflect.Float64:
+		return "double", nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported Avro field type %s", t)
 }
 
-// WriteToJSONL writes the DataFrame to a JSONL file
-func (df *DataFrame[T]) WriteToJSONL(filePath string) error {
+// avroSchemaFor derives an Avro record schema from a struct type. Field names follow the
+// json tags (or field names) and embedded structs are flattened, matching the Avro codec.
+func avroSchemaFor(t reflect.Type) (string, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("avro schema requires a struct type, got %v", t)
+	}
+
+	// Avro names may only contain letters, digits and underscores
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, t.Name())
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "Record" + name
+	}
+
+	var fields []map[string]interface{}
+	for _, col := range fieldColumnsFor(t) {
+		fieldType := t.FieldByIndex(col.Index).Type
+		avroType, err := avroTypeFor(fieldType)
+		if err != nil {
+			return "", fmt.Errorf("field '%s': %w", col.Name, err)
+		}
+		field := map[string]interface{}{"name": col.Name, "type": avroType}
+		if fieldType.Kind() == reflect.Ptr {
+			field["default"] = nil
+		}
+		fields = append(fields, field)
+	}
+
+	schema, err := json.Marshal(map[string]interface{}{
+		"type":   "record",
+		"name":   name,
+		"fields": fields,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal avro schema: %w", err)
+	}
+	return string(schema), nil
+}
+
+// WriteToAvro writes the DataFrame to a local Avro object container file
+func (df *DataFrame[T]) WriteToAvro(filePath string) error {
+	var empty T
+	schema, err := avroSchemaFor(reflect.TypeOf(empty))
+	if err != nil {
+		return err
+	}
+
 	// Create parent directories if they don't exist
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory '%s': %w", dir, err)
 	}
 
-	// Create or truncate the output file
 	file, err := os.Create(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to create JSONL file '%s': %w", filePath, err)
+		return fmt.Errorf("failed to create Avro file '%s': %w", filePath, err)
 	}
 	defer file.Close()
 
-	// Create a buffered writer for better performance
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
+	parsedSchema, err := avro.Parse(schema)
+	if err != nil {
+		return fmt.Errorf("failed to parse avro schema: %w", err)
+	}
+
+	// The container header must be written with the default config, so records are
+	// encoded separately with avroAPI and appended as raw bytes
+	enc, err := ocf.NewEncoderWithSchema(parsedSchema, file)
+	if err != nil {
+		return fmt.Errorf("failed to create avro encoder: %w", err)
+	}
 
-	// Process each record
 	for i, record := range df.Records {
-		// Marshal the record to JSON
-		jsonBytes, err := json.Marshal(record)
+		encoded, err := avroAPI.Marshal(parsedSchema, record)
 		if err != nil {
-			return fmt.Errorf("failed to marshal record at index %d: %w", i, err)
+			return fmt.Errorf("failed to encode record at index %d: %w", i, err)
 		}
-
-		// Write the JSON line with a newline character
-		if _, err := writer.Write(jsonBytes); err != nil {
+		if _, err := enc.Write(encoded); err != nil {
 			return fmt.Errorf("failed to write record at index %d: %w", i, err)
 		}
-		if _, err := writer.Write([]byte("\n")); err != nil {
-			return fmt.Errorf("failed to write newline at index %d: %w", i, err)
-		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to finalize Avro file '%s': %w", filePath, err)
 	}
 
 	return nil
 }
 
-// ReadFromJSONL reads a DataFrame from a JSONL file
-func ReadFromJSONL[T any](filePath string) (*DataFrame[T], error) {
-	// Open the file
+// ReadFromAvro reads a DataFrame from a local Avro object container file
+func ReadFromAvro[T any](filePath string) (*DataFrame[T], error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open JSONL file '%s': %w", filePath, err)
+		return nil, fmt.Errorf("failed to open Avro file '%s': %w", filePath, err)
 	}
 	defer file.Close()
 
-	// Create a scanner to read line by line
-	scanner := bufio.NewScanner(file)
-
-	// For large JSON objects, increase the buffer size if needed
-	const maxCapacity = 10 * 1024 * 1024 // 10MB
-	buf := make([]byte, 0, 64*1024)      // Start with 64KB
-	scanner.Buffer(buf, maxCapacity)
-
-	// Parse each line into a record
-	var records []T
-	lineNum := 0
-
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Bytes()
-
-		// Skip empty lines
-		if len(bytes.TrimSpace(line)) == 0 {
-			continue
-		}
+	dec, err := ocf.NewDecoder(file, ocf.WithDecoderConfig(avroAPI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create avro decoder: %w", err)
+	}
 
-		// Parse the JSON into a record
+	records := make([]T, 0)
+	for dec.HasNext() {
 		var record T
-		if err := json.Unmarshal(line, &record); err != nil {
-			return nil, fmt.Errorf("failed to parse JSONL at line %d: %w", lineNum, err)
+		if err := dec.Decode(&record); err != nil {
+			return nil, fmt.Errorf("failed to decode record at index %d: %w", len(records), err)
 		}
-
 		records = append(records, record)
 	}
-
-	// Check for scanner errors
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading JSONL file: %w", err)
+	if err := dec.Error(); err != nil {
+		return nil, fmt.Errorf("error reading Avro file: %w", err)
 	}
 
-	// Create and return the DataFrame
 	return CreateDataFrame(records), nil
 }
@@ -1,13 +1,27 @@
 package datarizer
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -15,6 +29,11 @@ import (
 	awsS3 "github.com/aws/aws-sdk-go/service/s3" // Use alias to avoid conflict
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/schema"
 )
 
 // Happy path for the test file
@@ -290,6 +309,2996 @@ func TestParseAndParquet(t *testing.T) {
 	t.Logf("Successfully verified %d records with RecordInfo", len(originalDF.Records))
 }
 
+// TestFilter tests that Filter keeps only matching records and leaves the source untouched
+func TestFilter(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1},
+		{Name: "Bob", Age: 22, Id: 2},
+		{Name: "Charlie", Age: 25, Id: 3},
+	}
+	df := CreateDataFrame(students)
+
+	filtered := df.Filter(func(s Student) bool { return s.Age > 21 })
+
+	if len(filtered.Records) != 2 {
+		t.Fatalf("Record count mismatch: expected=2, got=%d", len(filtered.Records))
+	}
+	if filtered.Records[0].Name != "Bob" || filtered.Records[1].Name != "Charlie" {
+		t.Errorf("Unexpected filtered records: %+v", filtered.Records)
+	}
+	if len(df.Records) != 3 {
+		t.Errorf("Original DataFrame was modified: got %d records", len(df.Records))
+	}
+
+	// An empty result should still have a non-nil slice
+	none := df.Filter(func(s Student) bool { return false })
+	if none.Records == nil || len(none.Records) != 0 {
+		t.Errorf("Expected non-nil empty slice, got %#v", none.Records)
+	}
+}
+
+// TestValidate tests collecting rule failures by record index
+func TestValidate(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1},
+		{Name: "Bob", Age: -1, Id: 2},
+		{Name: "", Age: 25, Id: 3},
+		{Name: "", Age: -5, Id: 4},
+	}
+	df := CreateDataFrame(students)
+
+	errNegativeAge := errors.New("age must not be negative")
+	nonNegativeAge := func(s Student) error {
+		if s.Age < 0 {
+			return errNegativeAge
+		}
+		return nil
+	}
+	nonEmptyName := func(s Student) error {
+		if s.Name == "" {
+			return fmt.Errorf("name must not be empty")
+		}
+		return nil
+	}
+
+	errs := df.Validate(nonNegativeAge)
+	if len(errs) != 2 || errs[0].Index != 1 || errs[1].Index != 3 {
+		t.Fatalf("Unexpected validation errors: %v", errs)
+	}
+	if !errors.Is(errs[0], errNegativeAge) {
+		t.Errorf("Expected RecordError to unwrap to the rule error, got %v", errs[0])
+	}
+
+	// Every failing rule is reported, in record then rule order
+	errs = df.Validate(nonNegativeAge, nonEmptyName)
+	var indexes []int
+	for _, e := range errs {
+		indexes = append(indexes, e.Index)
+	}
+	if fmt.Sprint(indexes) != fmt.Sprint([]int{1, 2, 3, 3}) {
+		t.Errorf("Unexpected failing indexes: %v", indexes)
+	}
+
+	if errs := df.Head(1).Validate(nonNegativeAge, nonEmptyName); errs != nil {
+		t.Errorf("Expected no validation errors, got %v", errs)
+	}
+}
+
+// TestChunk tests splitting a DataFrame into fixed-size chunks
+func TestChunk(t *testing.T) {
+	students := make([]Student, 10)
+	for i := range students {
+		students[i] = Student{Name: fmt.Sprintf("student-%d", i), Id: int64(i)}
+	}
+	df := CreateDataFrame(students)
+
+	chunks := df.Chunk(3)
+	var sizes []int
+	var ids []int64
+	for _, chunk := range chunks {
+		sizes = append(sizes, len(chunk.Records))
+		for _, record := range chunk.Records {
+			ids = append(ids, record.Id)
+		}
+		if chunk.schema != df.schema {
+			t.Errorf("Expected chunks to share the schema reference")
+		}
+	}
+	if !slices.Equal(sizes, []int{3, 3, 3, 1}) {
+		t.Errorf("Chunk sizes mismatch: expected=[3 3 3 1], got=%v", sizes)
+	}
+	if !slices.Equal(ids, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Errorf("Chunks out of order: %v", ids)
+	}
+
+	// Appending to a chunk must not affect the source
+	chunks[0].Records = append(chunks[0].Records, Student{Name: "Dave"})
+	if df.Records[3].Name != "student-3" {
+		t.Errorf("Original DataFrame was modified: %+v", df.Records[3])
+	}
+
+	for _, size := range []int{0, -1} {
+		if whole := df.Chunk(size); len(whole) != 1 || len(whole[0].Records) != len(students) {
+			t.Errorf("Expected Chunk(%d) to return one chunk with every record, got %d chunks", size, len(whole))
+		}
+	}
+	if exact := df.Chunk(10); len(exact) != 1 {
+		t.Errorf("Expected Chunk(10) to return one chunk, got %d", len(exact))
+	}
+	if empty := CreateDataFrame([]Student{}).Chunk(3); len(empty) != 0 {
+		t.Errorf("Expected no chunks for an empty DataFrame, got %d", len(empty))
+	}
+}
+
+// TestCountIsEmpty tests counting records, including on a nil DataFrame
+func TestCountIsEmpty(t *testing.T) {
+	tests := []struct {
+		name      string
+		df        *DataFrame[Student]
+		wantCount int
+	}{
+		{name: "populated", df: CreateDataFrame([]Student{{Name: "Alice"}, {Name: "Bob"}}), wantCount: 2},
+		{name: "empty", df: CreateDataFrame([]Student{}), wantCount: 0},
+		{name: "nil records", df: CreateDataFrame[Student](nil), wantCount: 0},
+		{name: "nil frame", df: nil, wantCount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.df.Count(); got != tt.wantCount {
+				t.Errorf("Count mismatch: expected=%d, got=%d", tt.wantCount, got)
+			}
+			if got := tt.df.IsEmpty(); got != (tt.wantCount == 0) {
+				t.Errorf("IsEmpty mismatch: expected=%v, got=%v", tt.wantCount == 0, got)
+			}
+		})
+	}
+}
+
+// TestHeadTail tests taking the first and last records of a DataFrame
+func TestHeadTail(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1},
+		{Name: "Bob", Age: 22, Id: 2},
+		{Name: "Charlie", Age: 25, Id: 3},
+	}
+	df := CreateDataFrame(students)
+
+	head := df.Head(2)
+	if len(head.Records) != 2 || head.Records[0].Name != "Alice" || head.Records[1].Name != "Bob" {
+		t.Errorf("Unexpected Head(2) records: %+v", head.Records)
+	}
+	if head.schema != df.schema {
+		t.Errorf("Expected Head to share the schema reference")
+	}
+
+	// Appending to the result must not affect the source
+	head.Records = append(head.Records, Student{Name: "Dave"})
+	if df.Records[2].Name != "Charlie" {
+		t.Errorf("Original DataFrame was modified: %+v", df.Records)
+	}
+
+	if all := df.Head(100); len(all.Records) != 3 {
+		t.Errorf("Record count mismatch for Head(100): expected=3, got=%d", len(all.Records))
+	}
+	if none := df.Head(-1); len(none.Records) != 0 {
+		t.Errorf("Record count mismatch for Head(-1): expected=0, got=%d", len(none.Records))
+	}
+
+	tail := df.Tail(2)
+	if len(tail.Records) != 2 || tail.Records[0].Name != "Bob" || tail.Records[1].Name != "Charlie" {
+		t.Errorf("Unexpected Tail(2) records: %+v", tail.Records)
+	}
+	if none := df.Tail(0); none.Records == nil || len(none.Records) != 0 {
+		t.Errorf("Expected non-nil empty slice for Tail(0), got %#v", none.Records)
+	}
+}
+
+// TestDescribe tests computing summary statistics for numeric columns
+func TestDescribe(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1, Weight: 60.5},
+		{Name: "Bob", Age: 22, Id: 2, Weight: 70.5},
+		{Name: "Charlie", Age: 27, Id: 3, Weight: 80},
+	}
+	df := CreateDataFrame(students)
+
+	stats, err := df.Describe()
+	if err != nil {
+		t.Fatalf("Failed to describe DataFrame: %v", err)
+	}
+
+	age := stats["Age"]
+	if age.Count != 3 || age.Min != 20 || age.Max != 27 || age.Sum != 69 || age.Mean != 23 {
+		t.Errorf("Age stats mismatch: %+v", age)
+	}
+	weight := stats["Weight"]
+	if weight.Count != 3 || weight.Min != 60.5 || weight.Max != 80 || weight.Sum != 211 {
+		t.Errorf("Weight stats mismatch: %+v", weight)
+	}
+	if math.Abs(weight.Mean-211.0/3) > 1e-9 {
+		t.Errorf("Weight mean mismatch: expected=%f, got=%f", 211.0/3, weight.Mean)
+	}
+
+	// Nil pointers are not counted and non-numeric fields are skipped
+	if ignored := stats["Ignored"]; ignored.Count != 0 {
+		t.Errorf("Expected no values for Ignored, got %+v", ignored)
+	}
+	if _, ok := stats["Name"]; ok {
+		t.Errorf("Expected non-numeric field Name to be skipped")
+	}
+}
+
+// TestForEach tests visiting records until fn fails or the context is cancelled
+func TestForEach(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1},
+		{Name: "Bob", Age: 22, Id: 2},
+		{Name: "Charlie", Age: 25, Id: 3},
+	}
+	df := CreateDataFrame(students)
+
+	errPublish := errors.New("publish failed")
+	var visited []string
+	err := df.ForEach(context.Background(), func(ctx context.Context, s Student) error {
+		visited = append(visited, s.Name)
+		if s.Name == "Bob" {
+			return errPublish
+		}
+		return nil
+	})
+	if !errors.Is(err, errPublish) {
+		t.Fatalf("Expected publish error, got %v", err)
+	}
+	var recordErr RecordError
+	if !errors.As(err, &recordErr) || recordErr.Index != 1 {
+		t.Errorf("Expected RecordError at index 1, got %v", err)
+	}
+	if len(visited) != 2 || visited[1] != "Bob" {
+		t.Errorf("Expected iteration to stop at Bob, visited %v", visited)
+	}
+
+	// Cancelling the context stops iteration before the next record
+	ctx, cancel := context.WithCancel(context.Background())
+	visited = nil
+	err = df.ForEach(ctx, func(ctx context.Context, s Student) error {
+		visited = append(visited, s.Name)
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || len(visited) != 1 {
+		t.Errorf("Expected cancellation after one record, got err=%v visited=%v", err, visited)
+	}
+
+	if err := df.ForEach(context.Background(), func(ctx context.Context, s Student) error { return nil }); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+// TestForEachParallel tests that the first error stops the worker pool promptly
+func TestForEachParallel(t *testing.T) {
+	students := make([]Student, 1000)
+	for i := range students {
+		students[i] = Student{Name: fmt.Sprintf("Student%d", i), Id: int64(i)}
+	}
+	df := CreateDataFrame(students)
+
+	var calls int32
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	err := df.ForEachParallel(context.Background(), 8, func(ctx context.Context, s Student) error {
+		atomic.AddInt32(&calls, 1)
+		mu.Lock()
+		seen[s.Id] = true
+		mu.Unlock()
+		if s.Id == 10 {
+			return fmt.Errorf("cannot publish %s", s.Name)
+		}
+		// Simulate I/O that honours cancellation
+		select {
+		case <-time.After(time.Millisecond):
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	var recordErr RecordError
+	if !errors.As(err, &recordErr) || recordErr.Index != 10 {
+		t.Fatalf("Expected RecordError at index 10, got %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n >= int32(len(students)) {
+		t.Errorf("Expected the pool to stop early, fn was called %d times", n)
+	}
+
+	// Without errors every record is visited exactly once
+	atomic.StoreInt32(&calls, 0)
+	seen = make(map[int64]bool)
+	err = df.ForEachParallel(context.Background(), 8, func(ctx context.Context, s Student) error {
+		atomic.AddInt32(&calls, 1)
+		mu.Lock()
+		seen[s.Id] = true
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachParallel returned error: %v", err)
+	}
+	if calls != int32(len(students)) || len(seen) != len(students) {
+		t.Errorf("Expected %d records visited once, got calls=%d distinct=%d", len(students), calls, len(seen))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := df.ForEachParallel(ctx, 8, func(ctx context.Context, s Student) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// TestSample tests reproducible sampling without replacement
+func TestSample(t *testing.T) {
+	students := make([]Student, 100)
+	for i := range students {
+		students[i] = Student{Name: fmt.Sprintf("Student%d", i), Id: int64(i)}
+	}
+	df := CreateDataFrame(students)
+
+	sample := df.Sample(10, 42)
+	if len(sample.Records) != 10 {
+		t.Fatalf("Sample size mismatch: expected=10, got=%d", len(sample.Records))
+	}
+	seen := make(map[int64]bool)
+	for _, s := range sample.Records {
+		if s.Id < 0 || s.Id >= 100 || students[s.Id] != s {
+			t.Errorf("Sampled record not from the source: %+v", s)
+		}
+		if seen[s.Id] {
+			t.Errorf("Record %d sampled twice", s.Id)
+		}
+		seen[s.Id] = true
+	}
+
+	// The same seed gives the same sample
+	again := df.Sample(10, 42)
+	for i := range sample.Records {
+		if sample.Records[i] != again.Records[i] {
+			t.Fatalf("Sample with the same seed differs at index %d: %+v vs %+v", i, sample.Records[i], again.Records[i])
+		}
+	}
+
+	// Asking for more than available returns every record once
+	all := df.Sample(1000, 7)
+	if len(all.Records) != len(students) {
+		t.Errorf("Expected all %d records, got %d", len(students), len(all.Records))
+	}
+	if none := df.Sample(-1, 7); len(none.Records) != 0 {
+		t.Errorf("Expected no records for a negative n, got %d", len(none.Records))
+	}
+
+	for i, s := range df.Records {
+		if s.Id != int64(i) {
+			t.Fatalf("Source DataFrame was modified at index %d: %+v", i, s)
+		}
+	}
+}
+
+// TestMergeByKey tests inner and left joins of students with weight updates
+func TestMergeByKey(t *testing.T) {
+	type WeightUpdate struct {
+		StudentId int64
+		Weight    float32
+	}
+
+	students := CreateDataFrame([]Student{
+		{Name: "Alice", Age: 20, Id: 1, Weight: 50},
+		{Name: "Bob", Age: 22, Id: 2, Weight: 60},
+		{Name: "Charlie", Age: 25, Id: 3, Weight: 70},
+	})
+	updates := CreateDataFrame([]WeightUpdate{
+		{StudentId: 3, Weight: 72},
+		{StudentId: 1, Weight: 51},
+		{StudentId: 3, Weight: 73}, // Later updates win
+		{StudentId: 9, Weight: 99}, // No matching student
+	})
+
+	studentKey := func(s Student) string { return strconv.FormatInt(s.Id, 10) }
+	updateKey := func(u WeightUpdate) string { return strconv.FormatInt(u.StudentId, 10) }
+	applyWeight := func(s Student, u WeightUpdate) Student {
+		s.Weight = u.Weight
+		return s
+	}
+
+	inner := MergeByKey(students, updates, studentKey, updateKey, applyWeight, InnerJoin)
+	if len(inner.Records) != 2 {
+		t.Fatalf("Inner join record count mismatch: expected=2, got=%d", len(inner.Records))
+	}
+	if inner.Records[0].Name != "Alice" || inner.Records[0].Weight != 51 ||
+		inner.Records[1].Name != "Charlie" || inner.Records[1].Weight != 73 {
+		t.Errorf("Unexpected inner join records: %+v", inner.Records)
+	}
+
+	left := MergeByKey(students, updates, studentKey, updateKey, applyWeight, LeftJoin)
+	weights := make([]float32, len(left.Records))
+	for i, s := range left.Records {
+		weights[i] = s.Weight
+	}
+	if fmt.Sprint(weights) != "[51 60 73]" {
+		t.Errorf("Unexpected left join weights: %v", weights)
+	}
+
+	// Inputs are left untouched
+	if students.Records[0].Weight != 50 || students.Records[2].Weight != 70 {
+		t.Errorf("Left DataFrame was modified: %+v", students.Records)
+	}
+}
+
+// TestStream tests streaming records and stopping early via context cancellation
+func TestStream(t *testing.T) {
+	records := make([]Student, 10)
+	for i := range records {
+		records[i] = Student{Id: int64(i)}
+	}
+	df := CreateDataFrame(records)
+
+	// A full stream yields every record in order
+	i := 0
+	for record := range df.Stream(context.Background()) {
+		if record.Id != int64(i) {
+			t.Errorf("Id mismatch at index %d: got %d", i, record.Id)
+		}
+		i++
+	}
+	if i != len(records) {
+		t.Errorf("Record count mismatch: expected=%d, got=%d", len(records), i)
+	}
+
+	// Consume half, then cancel
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := df.Stream(ctx)
+	for i := 0; i < len(records)/2; i++ {
+		<-stream
+	}
+	cancel()
+
+	// The producer must close the channel promptly, sending at most one more record
+	deadline := time.After(time.Second)
+	received := 0
+	for {
+		select {
+		case _, ok := <-stream:
+			if !ok {
+				if received > 1 {
+					t.Errorf("Expected at most one record after cancel, got %d", received)
+				}
+				return
+			}
+			received++
+		case <-deadline:
+			t.Fatal("Stream goroutine did not exit after cancellation")
+		}
+	}
+}
+
+// TestAppend tests adding records to a DataFrame incrementally
+func TestAppend(t *testing.T) {
+	df := CreateDataFrame([]Student{})
+	schema := df.schema
+
+	df.Append(Student{Name: "Alice", Id: 1}).Append(Student{Name: "Bob", Id: 2}, Student{Name: "Charlie", Id: 3})
+
+	if len(df.Records) != 3 {
+		t.Fatalf("Record count mismatch: expected=3, got=%d", len(df.Records))
+	}
+	for i, name := range []string{"Alice", "Bob", "Charlie"} {
+		if df.Records[i].Name != name {
+			t.Errorf("Name mismatch at index %d: expected=%s, got=%s", i, name, df.Records[i].Name)
+		}
+	}
+	if df.schema != schema {
+		t.Errorf("Expected Append to keep the schema reference")
+	}
+}
+
+// TestMap tests transforming records into a new element type
+func TestMap(t *testing.T) {
+	type SlimStudent struct {
+		Name string `json:"name"`
+		Age  int32  `json:"age"`
+	}
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1, RecordInfo: RecordInfo{RawData: "{}"}},
+		{Name: "Bob", Age: 22, Id: 2, RecordInfo: RecordInfo{RawData: "{}"}},
+	}
+	df := CreateDataFrame(students)
+
+	slim := Map(df, func(s Student) SlimStudent {
+		return SlimStudent{Name: s.Name, Age: s.Age}
+	})
+
+	if len(slim.Records) != len(students) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(students), len(slim.Records))
+	}
+	for i, s := range students {
+		if slim.Records[i].Name != s.Name || slim.Records[i].Age != s.Age {
+			t.Errorf("Record %d mismatch: got %+v", i, slim.Records[i])
+		}
+	}
+
+	// nil and empty inputs should yield an empty DataFrame
+	fromNil := Map[Student, SlimStudent](nil, func(s Student) SlimStudent { return SlimStudent{} })
+	if fromNil == nil || len(fromNil.Records) != 0 {
+		t.Errorf("Expected empty DataFrame from nil input, got %+v", fromNil)
+	}
+	fromEmpty := Map(CreateDataFrame([]Student{}), func(s Student) SlimStudent { return SlimStudent{} })
+	if len(fromEmpty.Records) != 0 {
+		t.Errorf("Expected empty DataFrame from empty input, got %d records", len(fromEmpty.Records))
+	}
+}
+
+// TestColumns tests listing the column names of a struct type
+func TestColumns(t *testing.T) {
+	expected := []string{
+		"Name", "Age", "Id", "Weight", "Sex", "Day", "Ignored",
+		"_raw_data", "_row_hash", "_ingest_timestamp", "_source_info",
+	}
+	if got := Columns[Student](); !slices.Equal(got, expected) {
+		t.Errorf("Columns mismatch for Student:\nexpected=%v\ngot=%v", expected, got)
+	}
+
+	type Tagged struct {
+		ID      int    `json:"id,omitempty"`
+		Secret  string `json:"-"`
+		private int
+		Email   string
+	}
+	if got := Columns[Tagged](); !slices.Equal(got, []string{"id", "Email"}) {
+		t.Errorf("Columns mismatch for Tagged: expected=[id Email], got=%v", got)
+	}
+
+	if got := Columns[map[string]interface{}](); got != nil {
+		t.Errorf("Expected no columns for a map type, got %v", got)
+	}
+}
+
+// TestLocalCSV tests writing to and reading from a local CSV file
+func TestLocalCSV(t *testing.T) {
+	type TestStudent struct {
+		Name     string  `json:"name"`
+		Age      int32   `json:"age"`
+		Id       int64   `json:"id"`
+		Weight   float32 `json:"weight"`
+		Enrolled bool
+		Ignored  *int32 `json:"ignored"`
+	}
+	ignored := int32(7)
+	// Create test data
+	students := []TestStudent{
+		{Name: "Alice", Age: 20, Id: 1, Weight: 60.5, Enrolled: true},
+		{Name: "Doe, Bob", Age: 22, Id: 2, Weight: 70.3, Ignored: &ignored},
+		{Name: "Charlie\n\"Chuck\"", Age: 25, Id: 3, Weight: 80.1},
+	}
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	// Create a temporary file for testing
+	tempFile := filepath.Join(dirPath, "test_students.csv")
+	defer os.Remove(tempFile) // Clean up after test
+
+	// Create DataFrame and write to CSV
+	originalDF := CreateDataFrame(students)
+	err := originalDF.WriteToCSV(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to write to CSV: %v", err)
+	}
+
+	// Read the CSV file back into a DataFrame
+	readDF, err := ReadFromCSV[TestStudent](tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read from CSV: %v", err)
+	}
+
+	// Compare the DataFrames
+	if len(originalDF.Records) != len(readDF.Records) {
+		t.Fatalf("Record count mismatch: original=%d, read=%d",
+			len(originalDF.Records), len(readDF.Records))
+	}
+
+	// Compare each record
+	for i := 0; i < len(originalDF.Records); i++ {
+		orig := originalDF.Records[i]
+		read := readDF.Records[i]
+
+		if orig.Name != read.Name {
+			t.Errorf("Name mismatch at index %d: original=%q, read=%q", i, orig.Name, read.Name)
+		}
+		if orig.Age != read.Age || orig.Id != read.Id || orig.Weight != read.Weight || orig.Enrolled != read.Enrolled {
+			t.Errorf("Record %d data mismatch: original=%+v, read=%+v", i, orig, read)
+		}
+		if (orig.Ignored == nil) != (read.Ignored == nil) || (orig.Ignored != nil && *orig.Ignored != *read.Ignored) {
+			t.Errorf("Ignored mismatch at index %d", i)
+		}
+	}
+
+	// A missing column should produce a clear error
+	type WiderStudent struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if _, err := ReadFromCSV[WiderStudent](tempFile); err == nil || !strings.Contains(err.Error(), "email") {
+		t.Errorf("Expected missing column error mentioning 'email', got %v", err)
+	}
+
+	t.Logf("Successfully verified %d records", len(originalDF.Records))
+}
+
+// TestLocalParquetColumns tests reading only a subset of columns from a Parquet file
+func TestLocalParquetColumns(t *testing.T) {
+	type TestStudent struct {
+		Name   string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		Age    int32   `parquet:"name=age, type=INT32"`
+		Id     int64   `parquet:"name=id, type=INT64"`
+		Weight float32 `parquet:"name=weight, type=FLOAT"`
+	}
+	students := []TestStudent{
+		{Name: "Alice", Age: 20, Id: 1, Weight: 60.5},
+		{Name: "Bob", Age: 22, Id: 2, Weight: 70.3},
+	}
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tempFile := filepath.Join(dirPath, "test_columns.parquet")
+	defer os.Remove(tempFile) // Clean up after test
+
+	if err := CreateDataFrame(students).WriteToLocalParquet(tempFile); err != nil {
+		t.Fatalf("Failed to write to Parquet: %v", err)
+	}
+
+	readDF, err := ReadFromLocalParquetColumns[TestStudent](tempFile, []string{"name", "id"})
+	if err != nil {
+		t.Fatalf("Failed to read columns from Parquet: %v", err)
+	}
+
+	if len(readDF.Records) != len(students) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(students), len(readDF.Records))
+	}
+	for i, student := range students {
+		read := readDF.Records[i]
+		if read.Name != student.Name || read.Id != student.Id {
+			t.Errorf("Projected columns mismatch at index %d: got %+v", i, read)
+		}
+		if read.Age != 0 || read.Weight != 0 {
+			t.Errorf("Unrequested columns were read at index %d: got %+v", i, read)
+		}
+	}
+
+	// Unknown columns should be rejected with a descriptive error
+	_, err = ReadFromLocalParquetColumns[TestStudent](tempFile, []string{"name", "email"})
+	if err == nil || !strings.Contains(err.Error(), "email") {
+		t.Errorf("Expected error mentioning missing column 'email', got %v", err)
+	}
+}
+
+// TestParquetRowGroupSize tests that a small RowGroupSize splits the output into several row groups
+func TestParquetRowGroupSize(t *testing.T) {
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+	students := make([]TestStudent, 20000)
+	for i := range students {
+		students[i] = TestStudent{Name: fmt.Sprintf("student-%d", i), Id: int64(i)}
+	}
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tempFile := filepath.Join(dirPath, "test_row_groups.parquet")
+	defer os.Remove(tempFile) // Clean up after test
+
+	config := DefaultParquetConfig()
+	config.RowGroupSize = 16 * 1024 // 16KB
+	if err := CreateDataFrame(students).WriteToLocalParquet(tempFile, config); err != nil {
+		t.Fatalf("Failed to write to Parquet: %v", err)
+	}
+
+	fr, err := local.NewLocalFileReader(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to open Parquet file: %v", err)
+	}
+	defer fr.Close()
+
+	pr, err := reader.NewParquetColumnReader(fr, 1)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet footer: %v", err)
+	}
+	if numRowGroups := len(pr.Footer.RowGroups); numRowGroups <= 1 {
+		t.Errorf("Expected more than one row group, got %d", numRowGroups)
+	}
+	if pr.GetNumRows() != int64(len(students)) {
+		t.Errorf("Row count mismatch: expected=%d, got=%d", len(students), pr.GetNumRows())
+	}
+}
+
+// TestConcat tests merging several DataFrames while preserving record order
+func TestConcat(t *testing.T) {
+	first := CreateDataFrame([]Student{{Name: "Alice"}, {Name: "Bob"}})
+	second := CreateDataFrame([]Student{})
+	third := CreateDataFrame([]Student{{Name: "Charlie"}, {Name: "Dave"}, {Name: "Eve"}})
+
+	merged := Concat(first, nil, second, third)
+
+	expected := []string{"Alice", "Bob", "Charlie", "Dave", "Eve"}
+	if len(merged.Records) != len(expected) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(expected), len(merged.Records))
+	}
+	for i, name := range expected {
+		if merged.Records[i].Name != name {
+			t.Errorf("Name mismatch at index %d: expected=%s, got=%s", i, name, merged.Records[i].Name)
+		}
+	}
+
+	// Source DataFrames must not be affected
+	if len(first.Records) != 2 || len(third.Records) != 3 {
+		t.Errorf("Source DataFrames were modified")
+	}
+
+	// Concatenating nothing yields an empty DataFrame
+	if empty := Concat[Student](nil); empty == nil || len(empty.Records) != 0 {
+		t.Errorf("Expected empty DataFrame, got %+v", empty)
+	}
+}
+
+// TestParseFromJsonCustomHasher tests that a custom Hasher is used to compute RowHash
+func TestParseFromJsonCustomHasher(t *testing.T) {
+	raw := []byte(`{"Name": "Alice", "Age": 22}`)
+
+	calls := 0
+	parser := BaseSchemaParser[Student]{
+		Hasher: func(data []byte) string {
+			calls++
+			if string(data) != string(raw) {
+				t.Errorf("Hasher received unexpected data: %s", data)
+			}
+			return "custom-hash"
+		},
+	}
+
+	student, err := parser.ParseFromJson(raw, "test_source")
+	if err != nil {
+		t.Fatalf("Failed to parse record: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected hasher to be called once, got %d", calls)
+	}
+	if student.RecordInfo.RowHash != "custom-hash" {
+		t.Errorf("RowHash mismatch: expected=custom-hash, got=%s", student.RecordInfo.RowHash)
+	}
+
+	// The zero-value parser keeps using SHA-256
+	defaultParser := BaseSchemaParser[Student]{}
+	student, err = defaultParser.ParseFromJson(raw, "test_source")
+	if err != nil {
+		t.Fatalf("Failed to parse record: %v", err)
+	}
+	if student.RecordInfo.RowHash != sha256Hex(raw) {
+		t.Errorf("Default RowHash mismatch: got=%s", student.RecordInfo.RowHash)
+	}
+}
+
+// TestParseFromJsonArray tests parsing a whole JSON array into enriched records
+func TestParseFromJsonArray(t *testing.T) {
+	jsonData := `[
+		{"Name": "Alice", "Age": 22, "Id": 1001, "Weight": 65.5, "Sex": false, "Day": 10957},
+		{"Name": "Bob", "Age": 23, "Id": 1002, "Weight": 72.5, "Sex": true, "Day": 10731},
+		{"Name": "Charlie", "Age": 25, "Id": 1003, "Weight": 68.3, "Sex": true, "Day": 11023}
+	]`
+
+	parser := BaseSchemaParser[Student]{}
+	sourceInfo := "test_source"
+
+	students, err := parser.ParseFromJsonArray([]byte(jsonData), sourceInfo)
+	if err != nil {
+		t.Fatalf("Failed to parse JSON array: %v", err)
+	}
+	if len(students) != 3 {
+		t.Fatalf("Record count mismatch: expected=3, got=%d", len(students))
+	}
+	for i, student := range students {
+		if student.RecordInfo.SourceInfo != sourceInfo {
+			t.Errorf("SourceInfo not set correctly at index %d", i)
+		}
+		if student.RecordInfo.RowHash == "" || student.RecordInfo.RawData == "" || student.RecordInfo.IngestTimestamp == 0 {
+			t.Errorf("RecordInfo not populated at index %d: %+v", i, student.RecordInfo)
+		}
+	}
+	if students[2].Name != "Charlie" {
+		t.Errorf("Unexpected record order: %+v", students)
+	}
+
+	// A single top-level object is parsed as one record
+	single, err := parser.ParseFromJsonArray([]byte(`{"Name": "Dave", "Age": 30}`), sourceInfo)
+	if err != nil {
+		t.Fatalf("Failed to parse single object: %v", err)
+	}
+	if len(single) != 1 || single[0].Name != "Dave" {
+		t.Errorf("Unexpected single-object result: %+v", single)
+	}
+
+	// Failing records are reported with their index
+	_, err = parser.ParseFromJsonArray([]byte(`[{"Name": "Eve"}, {"Age": "old"}]`), sourceInfo)
+	if err == nil || !strings.Contains(err.Error(), "index 1") {
+		t.Errorf("Expected error mentioning index 1, got %v", err)
+	}
+}
+
+// TestParseFromJsonMissingRecordInfo tests that a missing RecordInfo field is reported with a sentinel error
+func TestParseFromJsonMissingRecordInfo(t *testing.T) {
+	type PlainStudent struct {
+		Name string
+		Age  int32
+	}
+	parser := BaseSchemaParser[PlainStudent]{}
+
+	_, err := parser.ParseFromJson([]byte(`{"Name": "Alice", "Age": 22}`), "test_source")
+	if !errors.Is(err, ErrNoRecordInfoField) {
+		t.Fatalf("Expected ErrNoRecordInfoField, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "PlainStudent") {
+		t.Errorf("Expected error to mention the type name, got %v", err)
+	}
+}
+
+// TestParseFromJsonRequiredFields tests rejecting records that omit required keys
+func TestParseFromJsonRequiredFields(t *testing.T) {
+	type Student struct {
+		Name       string
+		Age        int32
+		RecordInfo RecordInfo
+	}
+	data := []byte(`{"Name": "Alice"}`)
+
+	// Without RequiredFields the missing Age is zero-filled
+	lenient := BaseSchemaParser[Student]{}
+	record, err := lenient.ParseFromJson(data, "test_source")
+	if err != nil {
+		t.Fatalf("Failed to parse record: %v", err)
+	}
+	if record.Age != 0 {
+		t.Errorf("Age mismatch: expected 0, got %d", record.Age)
+	}
+
+	strict := BaseSchemaParser[Student]{RequiredFields: []string{"Name", "Age"}}
+	record, err = strict.ParseFromJson(data, "test_source")
+	if !errors.Is(err, ErrMissingRequiredFields) {
+		t.Fatalf("Expected ErrMissingRequiredFields, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Age") || strings.Contains(err.Error(), "Name") {
+		t.Errorf("Expected error to name only Age, got %v", err)
+	}
+	if record.Name != "" {
+		t.Errorf("Expected no record on validation failure, got %+v", record)
+	}
+
+	if _, err := strict.ParseFromJson([]byte(`{"Name": "Bob", "Age": 22}`), "test_source"); err != nil {
+		t.Errorf("Failed to parse complete record: %v", err)
+	}
+}
+
+// TestParseFromJsonTransform tests normalizing records with a Transform hook
+func TestParseFromJsonTransform(t *testing.T) {
+	type Student struct {
+		Name       string
+		Age        int32
+		RecordInfo RecordInfo
+	}
+	data := []byte(`{"Name": "alice", "Age": 20}`)
+
+	parser := BaseSchemaParser[Student]{
+		Transform: func(s *Student) error {
+			if s.Age < 0 {
+				return fmt.Errorf("negative age %d", s.Age)
+			}
+			s.Name = strings.ToUpper(s.Name)
+			return nil
+		},
+	}
+	record, err := parser.ParseFromJson(data, "test_source")
+	if err != nil {
+		t.Fatalf("Failed to parse record: %v", err)
+	}
+	if record.Name != "ALICE" {
+		t.Errorf("Name mismatch: expected ALICE, got %s", record.Name)
+	}
+	if record.RecordInfo.RowHash != sha256Hex(data) {
+		t.Errorf("RowHash should be computed on the raw bytes, got %s", record.RecordInfo.RowHash)
+	}
+	if record.RecordInfo.RawData != string(data) {
+		t.Errorf("RawData mismatch: expected %s, got %s", data, record.RecordInfo.RawData)
+	}
+
+	_, err = parser.ParseFromJson([]byte(`{"Name": "bob", "Age": -1}`), "test_source")
+	if err == nil || !strings.Contains(err.Error(), "negative age") {
+		t.Errorf("Expected the transform error to reject the record, got %v", err)
+	}
+}
+
+// cancelAfterCtx is a context that reports cancellation after Err has been checked n times
+type cancelAfterCtx struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfterCtx) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+// TestLocalParquetContextCancel tests aborting a local Parquet write when the context is cancelled
+func TestLocalParquetContextCancel(t *testing.T) {
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+
+	records := make([]TestStudent, 1000)
+	for i := range records {
+		records[i] = TestStudent{Name: fmt.Sprintf("student_%d", i), Id: int64(i)}
+	}
+	df := CreateDataFrame(records)
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tempFile := filepath.Join(dirPath, "test_cancel.parquet")
+	defer os.Remove(tempFile)
+
+	// Cancel after half of the records have been written
+	ctx := &cancelAfterCtx{Context: context.Background(), n: len(records) / 2}
+	err := df.WriteToLocalParquetContext(ctx, tempFile)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	// An uncancelled context writes the full frame
+	if err := df.WriteToLocalParquetContext(context.Background(), tempFile); err != nil {
+		t.Fatalf("Failed to write parquet file: %v", err)
+	}
+	readDF, err := ReadFromLocalParquet[TestStudent](tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read parquet file: %v", err)
+	}
+	if len(readDF.Records) != len(records) {
+		t.Errorf("Record count mismatch: expected %d, got %d", len(records), len(readDF.Records))
+	}
+}
+
+// TestPartitionByIngestDate tests routing records to date partitions by ingest timestamp
+func TestPartitionByIngestDate(t *testing.T) {
+	fallback := time.Date(2024, 5, 7, 1, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+	partition := PartitionByIngestDate[Student](fallback)
+
+	ingested := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got := partition(Student{RecordInfo: RecordInfo{IngestTimestamp: ingested.UnixMilli()}}); got != "dt=2024-01-02" {
+		t.Errorf("Partition mismatch: expected=dt=2024-01-02, got=%s", got)
+	}
+	// Without a timestamp the fallback is used, converted to UTC
+	if got := partition(Student{}); got != "dt=2024-05-06" {
+		t.Errorf("Partition mismatch for zero timestamp: expected=dt=2024-05-06, got=%s", got)
+	}
+
+	type Plain struct{ Name string }
+	if got := PartitionByIngestDate[Plain](fallback)(Plain{Name: "Alice"}); got != "dt=2024-05-06" {
+		t.Errorf("Partition mismatch without RecordInfo: expected=dt=2024-05-06, got=%s", got)
+	}
+}
+
+// TestAppendToLocalParquet tests appending records to an existing Parquet file
+func TestAppendToLocalParquet(t *testing.T) {
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tempFile := filepath.Join(dirPath, "test_append.parquet")
+	os.Remove(tempFile)
+	defer os.Remove(tempFile)
+
+	df := CreateDataFrame([]TestStudent{
+		{Name: "Alice", Id: 1},
+		{Name: "Bob", Id: 2},
+		{Name: "Charlie", Id: 3},
+	})
+	if err := df.WriteToLocalParquet(tempFile); err != nil {
+		t.Fatalf("Failed to write parquet file: %v", err)
+	}
+
+	more := []TestStudent{{Name: "Dave", Id: 4}, {Name: "Eve", Id: 5}}
+	if err := AppendToLocalParquet(tempFile, more, DefaultParquetConfig()); err != nil {
+		t.Fatalf("Failed to append to parquet file: %v", err)
+	}
+
+	readDF, err := ReadFromLocalParquet[TestStudent](tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read parquet file: %v", err)
+	}
+	if len(readDF.Records) != 5 {
+		t.Fatalf("Record count mismatch: expected=5, got=%d", len(readDF.Records))
+	}
+	for i, read := range readDF.Records {
+		if read.Id != int64(i+1) {
+			t.Errorf("Id mismatch at index %d: expected=%d, got=%d", i, i+1, read.Id)
+		}
+	}
+
+	// No temp files should be left behind
+	leftovers, _ := filepath.Glob(filepath.Join(dirPath, ".test_append.parquet.*.tmp"))
+	if len(leftovers) != 0 {
+		t.Errorf("Temp files were not cleaned up: %v", leftovers)
+	}
+}
+
+// TestWriteToLocalParquetAtomic tests that a failed local write leaves no partial file behind
+func TestWriteToLocalParquetAtomic(t *testing.T) {
+	type LooseStudent struct {
+		Id    int64       `parquet:"name=id, type=INT64"`
+		Score interface{} `parquet:"name=score, type=INT64"`
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "students.parquet")
+
+	bad := CreateDataFrame([]LooseStudent{{Id: 1, Score: int64(90)}, {Id: 2, Score: "not a number"}})
+	if err := bad.WriteToLocalParquet(target); err == nil {
+		t.Fatalf("Expected an error writing a record of the wrong type")
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("Expected no file at the target path after a failed write, got %v", err)
+	}
+
+	good := CreateDataFrame([]LooseStudent{{Id: 1, Score: int64(90)}, {Id: 2, Score: int64(75)}})
+	if err := good.WriteToLocalParquet(target); err != nil {
+		t.Fatalf("Failed to write to Parquet: %v", err)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatalf("Expected the target file to exist: %v", err)
+	}
+	// The new file gets the same umask-derived mode as one created with os.Create
+	reference, err := os.Create(filepath.Join(t.TempDir(), "reference"))
+	if err != nil {
+		t.Fatalf("Failed to create reference file: %v", err)
+	}
+	reference.Close()
+	refInfo, err := os.Stat(reference.Name())
+	if err != nil {
+		t.Fatalf("Failed to stat reference file: %v", err)
+	}
+	if info.Mode().Perm() != refInfo.Mode().Perm() {
+		t.Errorf("Mode mismatch: expected=%v, got=%v", refInfo.Mode().Perm(), info.Mode().Perm())
+	}
+
+	// Rewriting keeps the mode of the existing file
+	if err := os.Chmod(target, 0600); err != nil {
+		t.Fatalf("Failed to chmod target: %v", err)
+	}
+	if err := good.WriteToLocalParquet(target); err != nil {
+		t.Fatalf("Failed to rewrite Parquet: %v", err)
+	}
+	if info, err := os.Stat(target); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the rewritten file to keep mode 0600, got %v (err: %v)", info.Mode().Perm(), err)
+	}
+
+	// A failed rewrite keeps the previous file intact
+	if err := bad.WriteToLocalParquet(target); err == nil {
+		t.Fatalf("Expected an error writing a record of the wrong type")
+	}
+	type TestStudent struct {
+		Id    int64 `parquet:"name=id, type=INT64"`
+		Score int64 `parquet:"name=score, type=INT64"`
+	}
+	readDF, err := ReadFromLocalParquet[TestStudent](target)
+	if err != nil {
+		t.Fatalf("Failed to read the previous file after a failed rewrite: %v", err)
+	}
+	if len(readDF.Records) != 2 || readDF.Records[1].Score != 75 {
+		t.Errorf("Unexpected records after a failed rewrite: %+v", readDF.Records)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to list output directory: %v", err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("Expected only the target file to remain, got %v", names)
+	}
+}
+
+// TestWriteToLocalParquetIfChanged tests skipping rewrites of a Parquet file with unchanged records
+func TestWriteToLocalParquetIfChanged(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "students.parquet")
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1, RecordInfo: RecordInfo{RawData: `{"Name":"Alice"}`, IngestTimestamp: 1000}},
+		{Name: "Bob", Age: 22, Id: 2, RecordInfo: RecordInfo{RawData: `{"Name":"Bob"}`, IngestTimestamp: 1000}},
+	}
+
+	written, err := CreateDataFrame(students).WriteToLocalParquetIfChanged(target)
+	if err != nil || !written {
+		t.Fatalf("Expected the first call to write, got written=%v err=%v", written, err)
+	}
+	if _, err := os.Stat(target + ".sha256"); err != nil {
+		t.Fatalf("Expected a sidecar hash file: %v", err)
+	}
+	first, err := os.Stat(target)
+	if err != nil {
+		t.Fatalf("Expected the Parquet file to exist: %v", err)
+	}
+
+	// The same records ingested later are still unchanged
+	rerun := slices.Clone(students)
+	for i := range rerun {
+		rerun[i].RecordInfo.IngestTimestamp = 2000
+	}
+	written, err = CreateDataFrame(rerun).WriteToLocalParquetIfChanged(target)
+	if err != nil || written {
+		t.Fatalf("Expected identical data to skip the write, got written=%v err=%v", written, err)
+	}
+	if second, err := os.Stat(target); err != nil || !second.ModTime().Equal(first.ModTime()) {
+		t.Errorf("Expected the Parquet file to be left untouched")
+	}
+
+	changed := slices.Clone(students)
+	changed[1].Age = 23
+	written, err = CreateDataFrame(changed).WriteToLocalParquetIfChanged(target)
+	if err != nil || !written {
+		t.Fatalf("Expected changed data to be written, got written=%v err=%v", written, err)
+	}
+	readDF, err := ReadFromLocalParquet[Student](target)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet: %v", err)
+	}
+	if readDF.Records[1].Age != 23 {
+		t.Errorf("Expected the rewritten file to hold the changed data, got %+v", readDF.Records[1])
+	}
+
+	// A missing Parquet file is rewritten even if the sidecar matches
+	if err := os.Remove(target); err != nil {
+		t.Fatalf("Failed to remove Parquet file: %v", err)
+	}
+	written, err = CreateDataFrame(changed).WriteToLocalParquetIfChanged(target)
+	if err != nil || !written {
+		t.Errorf("Expected a missing file to be written, got written=%v err=%v", written, err)
+	}
+}
+
+// TestWriteToParquetLenient tests skipping unserializable records while writing the rest
+func TestWriteToParquetLenient(t *testing.T) {
+	type LooseStudent struct {
+		Id    int64       `parquet:"name=id, type=INT64"`
+		Score interface{} `parquet:"name=score, type=INT64"`
+	}
+	type TestStudent struct {
+		Id    int64 `parquet:"name=id, type=INT64"`
+		Score int64 `parquet:"name=score, type=INT64"`
+	}
+
+	df := CreateDataFrame([]LooseStudent{
+		{Id: 1, Score: int64(90)},
+		{Id: 2, Score: "not a number"},
+		{Id: 3, Score: int64(75)},
+	})
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tempFile := filepath.Join(dirPath, "test_lenient.parquet")
+	defer os.Remove(tempFile)
+
+	fw, err := local.NewLocalFileWriter(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to create local writer: %v", err)
+	}
+	written, errs, err := df.WriteToParquetLenient(fw, DefaultParquetConfig())
+	fw.Close()
+	if err != nil {
+		t.Fatalf("Failed to write parquet file: %v", err)
+	}
+
+	if written != 2 {
+		t.Errorf("Written count mismatch: expected=2, got=%d", written)
+	}
+	if len(errs) != 1 || errs[0].Index != 1 {
+		t.Fatalf("Expected one record error at index 1, got %v", errs)
+	}
+
+	readDF, err := ReadFromLocalParquet[TestStudent](tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read parquet file: %v", err)
+	}
+	if len(readDF.Records) != 2 || readDF.Records[0].Id != 1 || readDF.Records[1].Id != 3 {
+		t.Errorf("Unexpected records read back: %+v", readDF.Records)
+	}
+}
+
+// TestEnrichRecordInfo tests populating RecordInfo on an already-built DataFrame
+func TestEnrichRecordInfo(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1},
+		{Name: "Bob", Age: 22, Id: 2},
+		{Name: "Alice", Age: 20, Id: 1, RecordInfo: RecordInfo{RawData: "{}"}},
+	}
+	df := CreateDataFrame(students)
+
+	if err := EnrichRecordInfo(df, "test_source"); err != nil {
+		t.Fatalf("Failed to enrich RecordInfo: %v", err)
+	}
+
+	for i, record := range df.Records {
+		if record.SourceInfo != "test_source" {
+			t.Errorf("SourceInfo mismatch at index %d: got %q", i, record.SourceInfo)
+		}
+		if record.IngestTimestamp == 0 {
+			t.Errorf("IngestTimestamp not set at index %d", i)
+		}
+		if len(record.RowHash) != 64 {
+			t.Errorf("RowHash not set at index %d: got %q", i, record.RowHash)
+		}
+	}
+	if df.Records[0].RowHash == df.Records[1].RowHash {
+		t.Errorf("Expected distinct records to have different hashes")
+	}
+	// The hash ignores existing RecordInfo contents
+	if df.Records[0].RowHash != df.Records[2].RowHash {
+		t.Errorf("Expected identical records to have the same hash")
+	}
+	if df.Records[2].RawData != "{}" {
+		t.Errorf("RawData was modified: got %q", df.Records[2].RawData)
+	}
+
+	type PlainStudent struct {
+		Name string
+	}
+	if err := EnrichRecordInfo(CreateDataFrame([]PlainStudent{{Name: "Alice"}}), "test_source"); !errors.Is(err, ErrNoRecordInfoField) {
+		t.Errorf("Expected ErrNoRecordInfoField, got %v", err)
+	}
+}
+
+// TestParseFromJsonFieldError tests that type mismatches name the offending field
+func TestParseFromJsonFieldError(t *testing.T) {
+	parser := BaseSchemaParser[Student]{}
+
+	_, err := parser.ParseFromJson([]byte(`{"Name": "Alice", "Age": "thirty"}`), "test_source")
+	if err == nil {
+		t.Fatal("Expected an error for a string Age")
+	}
+	if !strings.Contains(err.Error(), `field "Age"`) || !strings.Contains(err.Error(), "int32") {
+		t.Errorf("Expected error to mention Age and int32, got %v", err)
+	}
+
+	var fieldErr *FieldDecodeError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Age" {
+		t.Errorf("Expected a FieldDecodeError for Age, got %v", err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("Expected the original *json.UnmarshalTypeError to be wrapped, got %v", err)
+	}
+}
+
+// TestParseFromJsonPlain tests decoding records with and without a RecordInfo field
+func TestParseFromJsonPlain(t *testing.T) {
+	type PlainStudent struct {
+		Name string
+		Age  int32
+	}
+	plainParser := BaseSchemaParser[PlainStudent]{RequiredFields: []string{"Name"}}
+
+	plain, err := plainParser.ParseFromJsonPlain([]byte(`{"Name": "Alice", "Age": 22}`))
+	if err != nil {
+		t.Fatalf("Failed to parse plain record: %v", err)
+	}
+	if plain.Name != "Alice" || plain.Age != 22 {
+		t.Errorf("Unexpected plain record: %+v", plain)
+	}
+	if _, err := plainParser.ParseFromJsonPlain([]byte(`{"Age": 22}`)); !errors.Is(err, ErrMissingRequiredFields) {
+		t.Errorf("Expected ErrMissingRequiredFields, got %v", err)
+	}
+
+	// Types with RecordInfo decode the same way but are left unenriched
+	parser := BaseSchemaParser[Student]{}
+	student, err := parser.ParseFromJsonPlain([]byte(`{"Name": "Bob", "Age": 25}`))
+	if err != nil {
+		t.Fatalf("Failed to parse record: %v", err)
+	}
+	if student.Name != "Bob" || student.RecordInfo != (RecordInfo{}) {
+		t.Errorf("Unexpected record: %+v", student)
+	}
+}
+
+// TestParseFromJsonClock tests setting IngestTimestamp from an injected clock
+func TestParseFromJsonClock(t *testing.T) {
+	fixed := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	parser := BaseSchemaParser[Student]{Now: func() time.Time { return fixed }}
+
+	student, err := parser.ParseFromJson([]byte(`{"Name": "Alice", "Age": 22}`), "test_source")
+	if err != nil {
+		t.Fatalf("Failed to parse record: %v", err)
+	}
+	if student.IngestTimestamp != 1704164645006 {
+		t.Errorf("IngestTimestamp mismatch: expected=%d, got=%d", int64(1704164645006), student.IngestTimestamp)
+	}
+}
+
+// TestWriteParquetStream tests streaming records from a channel into a Parquet file
+func TestWriteParquetStream(t *testing.T) {
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+	const numRecords = 100000
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tempFile := filepath.Join(dirPath, "test_stream.parquet")
+	defer os.Remove(tempFile) // Clean up after test
+
+	fw, err := local.NewLocalFileWriter(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to create local writer: %v", err)
+	}
+
+	records := make(chan TestStudent)
+	go func() {
+		defer close(records)
+		for i := 0; i < numRecords; i++ {
+			records <- TestStudent{Name: fmt.Sprintf("student-%d", i), Id: int64(i)}
+		}
+	}()
+
+	if err := WriteParquetStream(fw, records, DefaultParquetConfig()); err != nil {
+		t.Fatalf("Failed to stream to Parquet: %v", err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+
+	readDF, err := ReadFromLocalParquet[TestStudent](tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read from Parquet: %v", err)
+	}
+	if len(readDF.Records) != numRecords {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", numRecords, len(readDF.Records))
+	}
+	last := readDF.Records[numRecords-1]
+	if last.Id != numRecords-1 || last.Name != fmt.Sprintf("student-%d", numRecords-1) {
+		t.Errorf("Unexpected last record: %+v", last)
+	}
+}
+
+// TestReadLocalParquetMetadata tests reading footer metadata without reading rows
+func TestReadLocalParquetMetadata(t *testing.T) {
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Age  int32  `parquet:"name=age, type=INT32"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+	students := []TestStudent{
+		{Name: "Alice", Age: 20, Id: 1},
+		{Name: "Bob", Age: 22, Id: 2},
+		{Name: "Charlie", Age: 25, Id: 3},
+	}
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tempFile := filepath.Join(dirPath, "test_metadata.parquet")
+	defer os.Remove(tempFile) // Clean up after test
+
+	if err := CreateDataFrame(students).WriteToLocalParquet(tempFile); err != nil {
+		t.Fatalf("Failed to write to Parquet: %v", err)
+	}
+
+	metadata, err := ReadLocalParquetMetadata(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet metadata: %v", err)
+	}
+
+	if metadata.NumRows != int64(len(students)) {
+		t.Errorf("NumRows mismatch: expected=%d, got=%d", len(students), metadata.NumRows)
+	}
+	if metadata.NumRowGroups != 1 {
+		t.Errorf("NumRowGroups mismatch: expected=1, got=%d", metadata.NumRowGroups)
+	}
+	if strings.Join(metadata.Columns, ",") != "name,age,id" {
+		t.Errorf("Columns mismatch: got %v", metadata.Columns)
+	}
+	if metadata.CreatedBy == "" {
+		t.Errorf("CreatedBy should be set")
+	}
+}
+
+// TestParquetSchemaName tests naming the root schema element of a Parquet file
+func TestParquetSchemaName(t *testing.T) {
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+	students := []TestStudent{{Name: "Alice", Id: 1}, {Name: "Bob", Id: 2}}
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tempFile := filepath.Join(dirPath, "test_schema_name.parquet")
+	defer os.Remove(tempFile)
+
+	// Without a name the library default is kept
+	if err := CreateDataFrame(students).WriteToLocalParquet(tempFile); err != nil {
+		t.Fatalf("Failed to write to Parquet: %v", err)
+	}
+	metadata, err := ReadLocalParquetMetadata(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet metadata: %v", err)
+	}
+	if metadata.SchemaName != "parquet_go_root" {
+		t.Errorf("SchemaName mismatch: expected=parquet_go_root, got=%s", metadata.SchemaName)
+	}
+
+	config := DefaultParquetConfig()
+	config.SchemaName = "students"
+	if err := CreateDataFrame(students).WriteToLocalParquet(tempFile, config); err != nil {
+		t.Fatalf("Failed to write to Parquet: %v", err)
+	}
+	metadata, err = ReadLocalParquetMetadata(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet metadata: %v", err)
+	}
+	if metadata.SchemaName != "students" {
+		t.Errorf("SchemaName mismatch: expected=students, got=%s", metadata.SchemaName)
+	}
+
+	// The renamed file still reads back normally
+	readDF, err := ReadFromLocalParquet[TestStudent](tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read parquet file: %v", err)
+	}
+	if len(readDF.Records) != 2 || readDF.Records[1].Name != "Bob" {
+		t.Errorf("Unexpected records read back: %+v", readDF.Records)
+	}
+}
+
+// TestParquetZstd tests a Zstandard round trip and compares the size against Snappy
+func TestParquetZstd(t *testing.T) {
+	type TestStudent struct {
+		Id      int64  `parquet:"name=id, type=INT64"`
+		RawData string `parquet:"name=_raw_data, type=BYTE_ARRAY, convertedtype=UTF8"`
+	}
+	students := make([]TestStudent, 5000)
+	for i := range students {
+		students[i] = TestStudent{
+			Id:      int64(i),
+			RawData: fmt.Sprintf(`{"name": "student_%d", "age": %d, "city": "Springfield", "notes": "%s"}`, i, i%50, strings.Repeat("lorem ipsum ", 10)),
+		}
+	}
+	df := CreateDataFrame(students)
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	zstdFile := filepath.Join(dirPath, "test_zstd.parquet")
+	snappyFile := filepath.Join(dirPath, "test_zstd_snappy.parquet")
+	defer os.Remove(zstdFile)
+	defer os.Remove(snappyFile)
+
+	if err := df.WriteToLocalParquet(zstdFile, ZstdParquetConfig()); err != nil {
+		t.Fatalf("Failed to write zstd parquet file: %v", err)
+	}
+	if err := df.WriteToLocalParquet(snappyFile, DefaultParquetConfig()); err != nil {
+		t.Fatalf("Failed to write snappy parquet file: %v", err)
+	}
+
+	readDF, err := ReadFromLocalParquet[TestStudent](zstdFile)
+	if err != nil {
+		t.Fatalf("Failed to read zstd parquet file: %v", err)
+	}
+	if len(readDF.Records) != len(students) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(students), len(readDF.Records))
+	}
+	for i := range students {
+		if readDF.Records[i] != students[i] {
+			t.Fatalf("Record mismatch at index %d", i)
+		}
+	}
+
+	// Check the footer to make sure the zstd codec was actually used
+	fr, err := local.NewLocalFileReader(zstdFile)
+	if err != nil {
+		t.Fatalf("Failed to open zstd parquet file: %v", err)
+	}
+	defer fr.Close()
+	pr, err := reader.NewParquetColumnReader(fr, 1)
+	if err != nil {
+		t.Fatalf("Failed to read parquet footer: %v", err)
+	}
+	for _, col := range pr.Footer.RowGroups[0].Columns {
+		if col.MetaData.Codec != parquet.CompressionCodec_ZSTD {
+			t.Errorf("Codec mismatch for %v: expected=ZSTD, got=%s", col.MetaData.PathInSchema, col.MetaData.Codec)
+		}
+	}
+
+	zstdInfo, err := os.Stat(zstdFile)
+	if err != nil {
+		t.Fatalf("Failed to stat zstd parquet file: %v", err)
+	}
+	snappyInfo, err := os.Stat(snappyFile)
+	if err != nil {
+		t.Fatalf("Failed to stat snappy parquet file: %v", err)
+	}
+	if zstdInfo.Size() >= snappyInfo.Size() {
+		t.Errorf("Expected zstd file to be smaller than snappy: zstd=%d, snappy=%d", zstdInfo.Size(), snappyInfo.Size())
+	}
+}
+
+// TestParquetBytes tests a Parquet round trip through an in-memory buffer
+func TestParquetBytes(t *testing.T) {
+	type TestStudent struct {
+		Name   string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Age    int32   `parquet:"name=age, type=INT32"`
+		Weight float32 `parquet:"name=weight, type=FLOAT"`
+	}
+	students := []TestStudent{
+		{Name: "Alice", Age: 20, Weight: 60.5},
+		{Name: "Bob", Age: 22, Weight: 70.3},
+		{Name: "Charlie", Age: 25, Weight: 80.1},
+	}
+
+	data, err := CreateDataFrame(students).ToParquetBytes()
+	if err != nil {
+		t.Fatalf("Failed to write Parquet bytes: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("PAR1")) {
+		t.Errorf("Expected Parquet magic bytes, got %q", data[:4])
+	}
+
+	readDF, err := ReadFromParquetBytes[TestStudent](data)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet bytes: %v", err)
+	}
+	if len(readDF.Records) != len(students) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(students), len(readDF.Records))
+	}
+	for i := range students {
+		if readDF.Records[i] != students[i] {
+			t.Errorf("Record mismatch at index %d: original=%+v, read=%+v", i, students[i], readDF.Records[i])
+		}
+	}
+}
+
+// TestSchemaJSONFor tests generating the Parquet JSON schema from struct tags
+func TestSchemaJSONFor(t *testing.T) {
+	schemaJSON, err := SchemaJSONFor[Student]()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+	for _, column := range []string{"name", "age", "id", "weight", "sex", "day", "ignored", "_recordinfo", "_raw_data", "_ingest_timestamp"} {
+		if !strings.Contains(schemaJSON, "name="+column+",") {
+			t.Errorf("Expected schema to contain column %s:\n%s", column, schemaJSON)
+		}
+	}
+	if !strings.Contains(schemaJSON, "logicaltype=TIMESTAMP") {
+		t.Errorf("Expected schema to keep logical types:\n%s", schemaJSON)
+	}
+
+	// The generated schema is accepted by the JSON schema writer and matches the struct schema
+	type Nested struct {
+		Tags  map[string]int32 `parquet:"name=tags, type=MAP, convertedtype=MAP, keytype=BYTE_ARRAY, keyconvertedtype=UTF8, valuetype=INT32"`
+		Names []string         `parquet:"name=names, type=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	}
+	nestedJSON, err := SchemaJSONFor[Nested]()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+	fromJSON, err := schema.NewSchemaHandlerFromJSON(nestedJSON)
+	if err != nil {
+		t.Fatalf("Generated schema was rejected: %v\n%s", err, nestedJSON)
+	}
+	fromStruct, err := schema.NewSchemaHandlerFromStruct(new(Nested))
+	if err != nil {
+		t.Fatalf("Failed to build schema from struct: %v", err)
+	}
+	if len(fromJSON.SchemaElements) != len(fromStruct.SchemaElements) {
+		t.Fatalf("Schema element count mismatch: struct=%d, json=%d", len(fromStruct.SchemaElements), len(fromJSON.SchemaElements))
+	}
+	for i := range fromStruct.SchemaElements {
+		if fromJSON.GetExName(i) != fromStruct.GetExName(i) {
+			t.Errorf("Schema element %d mismatch: struct=%s, json=%s", i, fromStruct.GetExName(i), fromJSON.GetExName(i))
+		}
+	}
+
+	// Invalid tags are reported
+	type BadTags struct {
+		Id int64 `parquet:"name=id, type=INT46"`
+	}
+	if _, err := SchemaJSONFor[BadTags](); err == nil {
+		t.Errorf("Expected an error for an invalid parquet type")
+	}
+}
+
+// TestRecordInfoParquetTypes tests that RecordInfo string columns are annotated as UTF8
+func TestRecordInfoParquetTypes(t *testing.T) {
+	parser := BaseSchemaParser[Student]{}
+	student, err := parser.ParseFromJson([]byte(`{"Name": "Alice", "Age": 20}`), "test_source")
+	if err != nil {
+		t.Fatalf("Failed to parse record: %v", err)
+	}
+
+	data, err := CreateDataFrame([]Student{student}).ToParquetBytes()
+	if err != nil {
+		t.Fatalf("Failed to write Parquet bytes: %v", err)
+	}
+
+	// Inspect the file schema as an external reader would see it
+	pr, err := reader.NewParquetColumnReader(buffer.NewBufferFileFromBytes(data), 1)
+	if err != nil {
+		t.Fatalf("Failed to read parquet footer: %v", err)
+	}
+	found := 0
+	for i, element := range pr.SchemaHandler.SchemaElements {
+		switch pr.SchemaHandler.GetExName(i) {
+		case "_raw_data", "_row_hash", "_source_info":
+			found++
+			if element.GetConvertedType() != parquet.ConvertedType_UTF8 {
+				t.Errorf("ConvertedType mismatch for %s: expected=UTF8, got=%v", pr.SchemaHandler.GetExName(i), element.ConvertedType)
+			}
+			if element.IsSetLogicalType() && !element.GetLogicalType().IsSetSTRING() {
+				t.Errorf("LogicalType mismatch for %s: expected=STRING, got=%v", pr.SchemaHandler.GetExName(i), element.GetLogicalType())
+			}
+		}
+	}
+	if found != 3 {
+		t.Errorf("Expected 3 RecordInfo string columns, found %d", found)
+	}
+}
+
+// TestWriteMapsToLocalParquet tests writing schemaless rows against a JSON schema
+func TestWriteMapsToLocalParquet(t *testing.T) {
+	schemaJSON := `{
+		"Tag": "name=parquet_go_root, repetitiontype=REQUIRED",
+		"Fields": [
+			{"Tag": "name=name, inname=Name, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED"},
+			{"Tag": "name=age, inname=Age, type=INT32, repetitiontype=REQUIRED"},
+			{"Tag": "name=email, inname=Email, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"}
+		]
+	}`
+	rows := []map[string]interface{}{
+		{"name": "Alice", "age": 20, "email": "alice@example.com"},
+		{"name": "Bob", "age": 22},
+		{"name": "Charlie", "age": 25, "email": nil},
+	}
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tempFile := filepath.Join(dirPath, "test_maps.parquet")
+	defer os.Remove(tempFile)
+
+	if err := WriteMapsToLocalParquet(tempFile, schemaJSON, rows, DefaultParquetConfig()); err != nil {
+		t.Fatalf("Failed to write maps to Parquet: %v", err)
+	}
+
+	metadata, err := ReadLocalParquetMetadata(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet metadata: %v", err)
+	}
+	if metadata.NumRows != int64(len(rows)) {
+		t.Errorf("NumRows mismatch: expected=%d, got=%d", len(rows), metadata.NumRows)
+	}
+
+	type TestStudent struct {
+		Name  string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Age   int32   `parquet:"name=age, type=INT32"`
+		Email *string `parquet:"name=email, type=BYTE_ARRAY, convertedtype=UTF8"`
+	}
+	readDF, err := ReadFromLocalParquet[TestStudent](tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read parquet file: %v", err)
+	}
+	if readDF.Records[0].Email == nil || *readDF.Records[0].Email != "alice@example.com" || readDF.Records[1].Email != nil {
+		t.Errorf("Unexpected records read back: %+v", readDF.Records)
+	}
+	if readDF.Records[2].Name != "Charlie" || readDF.Records[2].Age != 25 {
+		t.Errorf("Unexpected last record: %+v", readDF.Records[2])
+	}
+
+	// A failed write leaves the existing file untouched and no temporary file behind
+	badConfig := DefaultParquetConfig()
+	badConfig.Encodings = map[string]parquet.Encoding{"missing": parquet.Encoding_PLAIN}
+	if err := WriteMapsToLocalParquet(tempFile, schemaJSON, rows[:1], badConfig); err == nil {
+		t.Error("Expected an error for an encoding on an unknown column")
+	}
+	badRows := []map[string]interface{}{{"name": "Dave", "age": make(chan int)}}
+	if err := WriteMapsToLocalParquet(tempFile, schemaJSON, badRows, DefaultParquetConfig()); err == nil {
+		t.Error("Expected an error for a row that cannot be marshalled")
+	}
+	metadata, err = ReadLocalParquetMetadata(tempFile)
+	if err != nil || metadata.NumRows != int64(len(rows)) {
+		t.Errorf("Expected the existing file to be kept after failed writes, got %+v (err %v)", metadata, err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dirPath, ".test_maps.parquet.*.tmp")); len(leftovers) != 0 {
+		t.Errorf("Expected no temporary files after failed writes, got %v", leftovers)
+	}
+}
+
+// TestQueryChain tests chaining Where, Select and Limit
+func TestQueryChain(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1},
+		{Name: "Bob", Age: 22, Id: 2},
+		{Name: "Charlie", Age: 25, Id: 3},
+		{Name: "Dave", Age: 30, Id: 4},
+	}
+	df := CreateDataFrame(students)
+
+	selected, err := df.Where(func(s Student) bool { return s.Age > 20 }).Select("Name", "Age")
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	result := selected.Limit(2)
+
+	expected := []map[string]interface{}{
+		{"Name": "Bob", "Age": float64(22)},
+		{"Name": "Charlie", "Age": float64(25)},
+	}
+	if len(result.Records) != len(expected) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(expected), len(result.Records))
+	}
+	for i, row := range result.Records {
+		if len(row) != len(expected[i]) || row["Name"] != expected[i]["Name"] || row["Age"] != expected[i]["Age"] {
+			t.Errorf("Record mismatch at index %d: expected=%v, got=%v", i, expected[i], row)
+		}
+	}
+
+	// Each step leaves its input untouched
+	if len(df.Records) != 4 || len(selected.Records) != 3 {
+		t.Errorf("Query steps modified their input: df=%d, selected=%d", len(df.Records), len(selected.Records))
+	}
+
+	if _, err := df.Select("Missing"); err == nil {
+		t.Errorf("Expected error selecting a missing field")
+	}
+}
+
+// TestWriteToJSONLRenamed tests renaming JSON keys at write time
+func TestWriteToJSONLRenamed(t *testing.T) {
+	type TestStudent struct {
+		Name string `json:"name"`
+		Age  int32  `json:"age"`
+		Id   int64  `json:"id"`
+	}
+	df := CreateDataFrame([]TestStudent{
+		{Name: "Alice", Age: 20, Id: 1},
+		{Name: "Bob", Age: 22, Id: 2},
+	})
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	tempFile := filepath.Join(dirPath, "test_renamed.jsonl")
+	defer os.Remove(tempFile)
+
+	if err := df.WriteToJSONLRenamed(tempFile, map[string]string{"name": "full_name", "id": "student_id"}); err != nil {
+		t.Fatalf("Failed to write renamed JSONL: %v", err)
+	}
+	content, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read renamed JSONL: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Line count mismatch: expected=2, got=%d", len(lines))
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	keys := make([]string, 0, len(row))
+	for key := range row {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	if fmt.Sprint(keys) != "[age full_name student_id]" {
+		t.Errorf("Unexpected keys: %v", keys)
+	}
+	if row["full_name"] != "Bob" || row["student_id"] != float64(2) {
+		t.Errorf("Unexpected values: %v", row)
+	}
+
+	if err := df.WriteToJSONLRenamed(tempFile, map[string]string{"email": "mail"}); err == nil || !strings.Contains(err.Error(), "email") {
+		t.Errorf("Expected error for an unknown key, got %v", err)
+	}
+	if err := df.WriteToJSONLRenamed(tempFile, map[string]string{"name": "age"}); err == nil {
+		t.Errorf("Expected error for a colliding rename")
+	}
+}
+
+// TestWriteToJSONLWithOptions tests appending to a JSONL file and custom directory permissions
+func TestWriteToJSONLWithOptions(t *testing.T) {
+	type Row struct {
+		ID int `json:"id"`
+	}
+	dir := filepath.Join(t.TempDir(), "logs", "daily")
+	path := filepath.Join(dir, "rows.jsonl")
+	opts := JSONLOptions{DirPerm: 0750, Append: true}
+
+	if err := CreateDataFrame([]Row{{ID: 1}, {ID: 2}}).WriteToJSONLWithOptions(path, opts); err != nil {
+		t.Fatalf("Failed to write JSONL: %v", err)
+	}
+	if err := CreateDataFrame([]Row{{ID: 3}}).WriteToJSONLWithOptions(path, opts); err != nil {
+		t.Fatalf("Failed to append JSONL: %v", err)
+	}
+
+	readDF, err := ReadFromJSONL[Row](path)
+	if err != nil {
+		t.Fatalf("Failed to read JSONL: %v", err)
+	}
+	if !slices.Equal(readDF.Records, []Row{{ID: 1}, {ID: 2}, {ID: 3}}) {
+		t.Errorf("Expected records to accumulate across appends, got %+v", readDF.Records)
+	}
+
+	// The umask can only remove permission bits
+	for _, d := range []string{dir, filepath.Dir(dir)} {
+		info, err := os.Stat(d)
+		if err != nil {
+			t.Fatalf("Failed to stat directory: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm&^0750 != 0 || perm&0700 != 0700 {
+			t.Errorf("Directory permission mismatch for %s: expected 0750, got %v", d, perm)
+		}
+	}
+
+	// Without Append the file is truncated as with WriteToJSONL
+	if err := CreateDataFrame([]Row{{ID: 4}}).WriteToJSONLWithOptions(path, JSONLOptions{}); err != nil {
+		t.Fatalf("Failed to rewrite JSONL: %v", err)
+	}
+	readDF, err = ReadFromJSONL[Row](path)
+	if err != nil {
+		t.Fatalf("Failed to read JSONL: %v", err)
+	}
+	if !slices.Equal(readDF.Records, []Row{{ID: 4}}) {
+		t.Errorf("Expected the file to be truncated, got %+v", readDF.Records)
+	}
+}
+
+// TestSelectToJSONL tests writing a subset of JSON fields per record
+func TestSelectToJSONL(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1, Weight: 60.5},
+		{Name: "Bob", Age: 22, Id: 2, Weight: 70.3},
+	}
+	df := CreateDataFrame(students)
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tempFile := filepath.Join(dirPath, "test_select.jsonl")
+	defer os.Remove(tempFile) // Clean up after test
+
+	if err := df.SelectToJSONL(tempFile, "Name", "Age"); err != nil {
+		t.Fatalf("Failed to select to JSONL: %v", err)
+	}
+
+	readDF, err := ReadFromJSONL[map[string]interface{}](tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read from JSONL: %v", err)
+	}
+	if len(readDF.Records) != len(students) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(students), len(readDF.Records))
+	}
+	for i, line := range readDF.Records {
+		if len(line) != 2 {
+			t.Errorf("Expected only 2 keys at index %d, got %v", i, line)
+		}
+		if line["Name"] != students[i].Name || line["Age"] != float64(students[i].Age) {
+			t.Errorf("Selected values mismatch at index %d: got %v", i, line)
+		}
+	}
+
+	// Unknown fields should be rejected
+	if err := df.SelectToJSONL(tempFile, "Name", "Email"); err == nil || !strings.Contains(err.Error(), "Email") {
+		t.Errorf("Expected error mentioning 'Email', got %v", err)
+	}
+}
+
+// TestLocalParquetPartitioned tests writing one Parquet file per partition value
+func TestLocalParquetPartitioned(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 19, Id: 1},
+		{Name: "Bob", Age: 22, Id: 2},
+		{Name: "Charlie", Age: 25, Id: 3},
+		{Name: "Dave", Age: 31, Id: 4},
+		{Name: "Eve", Age: 18, Id: 5},
+	}
+
+	baseDir := filepath.Join("tmp", "test_partitioned")
+	defer os.RemoveAll(baseDir) // Clean up after test
+
+	decade := func(s Student) string { return fmt.Sprintf("decade=%d", s.Age/10*10) }
+	if err := CreateDataFrame(students).WriteToLocalParquetPartitioned(baseDir, decade); err != nil {
+		t.Fatalf("Failed to write partitioned Parquet: %v", err)
+	}
+
+	expected := map[string]int{"decade=10": 2, "decade=20": 2, "decade=30": 1}
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		t.Fatalf("Failed to list partition directory: %v", err)
+	}
+	if len(entries) != len(expected) {
+		t.Errorf("Partition count mismatch: expected=%d, got=%d", len(expected), len(entries))
+	}
+
+	for partition, count := range expected {
+		readDF, err := ReadFromLocalParquet[Student](filepath.Join(baseDir, partition, "part.parquet"))
+		if err != nil {
+			t.Fatalf("Failed to read partition %s: %v", partition, err)
+		}
+		if len(readDF.Records) != count {
+			t.Errorf("Record count mismatch in %s: expected=%d, got=%d", partition, count, len(readDF.Records))
+		}
+		for _, record := range readDF.Records {
+			if decade(record) != partition {
+				t.Errorf("Record %s written to wrong partition %s", record.Name, partition)
+			}
+		}
+	}
+}
+
+// TestSort tests stable in-place sorting and the non-mutating Sorted variant
+func TestSort(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20},
+		{Name: "Bob", Age: 25},
+		{Name: "Charlie", Age: 20},
+		{Name: "Dave", Age: 30},
+		{Name: "Eve", Age: 25},
+	}
+	byAgeDesc := func(a, b Student) bool { return a.Age > b.Age }
+	// Equal ages keep their original relative order
+	expected := []string{"Dave", "Bob", "Eve", "Alice", "Charlie"}
+
+	df := CreateDataFrame(students)
+	sorted := df.Sorted(byAgeDesc)
+	for i, name := range expected {
+		if sorted.Records[i].Name != name {
+			t.Errorf("Sorted order mismatch at index %d: expected=%s, got=%s", i, name, sorted.Records[i].Name)
+		}
+	}
+	if df.Records[0].Name != "Alice" || df.Records[4].Name != "Eve" {
+		t.Errorf("Sorted modified the original DataFrame: %+v", df.Records)
+	}
+
+	df.Sort(byAgeDesc)
+	for i, name := range expected {
+		if df.Records[i].Name != name {
+			t.Errorf("In-place order mismatch at index %d: expected=%s, got=%s", i, name, df.Records[i].Name)
+		}
+	}
+}
+
+// TestDedup tests dropping duplicate records by key while keeping the first occurrence
+func TestDedup(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", RecordInfo: RecordInfo{RowHash: "h1"}},
+		{Name: "Bob", RecordInfo: RecordInfo{RowHash: "h2"}},
+		{Name: "Alice again", RecordInfo: RecordInfo{RowHash: "h1"}},
+		{Name: "Charlie", RecordInfo: RecordInfo{RowHash: "h3"}},
+	}
+	df := CreateDataFrame(students)
+
+	deduped := df.Dedup(func(s Student) string { return s.RecordInfo.RowHash })
+
+	expected := []string{"Alice", "Bob", "Charlie"}
+	if len(deduped.Records) != len(expected) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(expected), len(deduped.Records))
+	}
+	for i, name := range expected {
+		if deduped.Records[i].Name != name {
+			t.Errorf("Name mismatch at index %d: expected=%s, got=%s", i, name, deduped.Records[i].Name)
+		}
+	}
+	if len(df.Records) != 4 {
+		t.Errorf("Original DataFrame was modified")
+	}
+
+	empty := CreateDataFrame([]Student{}).Dedup(func(s Student) string { return s.Name })
+	if empty.Records == nil || len(empty.Records) != 0 {
+		t.Errorf("Expected non-nil empty slice, got %#v", empty.Records)
+	}
+}
+
+// TestLocalAvro tests writing to and reading from a local Avro file
+func TestLocalAvro(t *testing.T) {
+	type TestStudent struct {
+		Name    string  `json:"name"`
+		Age     int32   `json:"age"`
+		Id      int64   `json:"id"`
+		Weight  float32 `json:"weight"`
+		Ignored *int32  `json:"ignored"`
+	}
+	ignored := int32(42)
+	// Create test data
+	students := []TestStudent{
+		{Name: "Alice", Age: 20, Id: 1, Weight: 60.5},
+		{Name: "Bob", Age: 22, Id: 2, Weight: 70.3, Ignored: &ignored},
+		{Name: "Charlie", Age: 25, Id: 3, Weight: 80.1},
+	}
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	// Create a temporary file for testing
+	tempFile := filepath.Join(dirPath, "test_students.avro")
+	defer os.Remove(tempFile) // Clean up after test
+
+	// Create DataFrame and write to Avro
+	originalDF := CreateDataFrame(students)
+	if err := originalDF.WriteToAvro(tempFile); err != nil {
+		t.Fatalf("Failed to write to Avro: %v", err)
+	}
+
+	// Read the Avro file back into a DataFrame
+	readDF, err := ReadFromAvro[TestStudent](tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read from Avro: %v", err)
+	}
+
+	if len(originalDF.Records) != len(readDF.Records) {
+		t.Fatalf("Record count mismatch: original=%d, read=%d",
+			len(originalDF.Records), len(readDF.Records))
+	}
+	for i := 0; i < len(originalDF.Records); i++ {
+		orig := originalDF.Records[i]
+		read := readDF.Records[i]
+
+		if orig.Name != read.Name || orig.Age != read.Age || orig.Id != read.Id || orig.Weight != read.Weight {
+			t.Errorf("Record %d data mismatch: original=%+v, read=%+v", i, orig, read)
+		}
+		if (orig.Ignored == nil) != (read.Ignored == nil) || (orig.Ignored != nil && *orig.Ignored != *read.Ignored) {
+			t.Errorf("Ignored mismatch at index %d", i)
+		}
+	}
+
+	t.Logf("Successfully verified %d records", len(originalDF.Records))
+}
+
+// TestReadFromLocalParquetDir tests reading a directory of Parquet files as one DataFrame
+func TestReadFromLocalParquetDir(t *testing.T) {
+	baseDir := filepath.Join("tmp", "test_parquet_dir")
+	defer os.RemoveAll(baseDir) // Clean up after test
+
+	first := CreateDataFrame([]Student{{Name: "Alice", Id: 1}, {Name: "Bob", Id: 2}})
+	second := CreateDataFrame([]Student{{Name: "Charlie", Id: 3}})
+	if err := os.MkdirAll(filepath.Join(baseDir, "nested"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := first.WriteToLocalParquet(filepath.Join(baseDir, "a.parquet")); err != nil {
+		t.Fatalf("Failed to write parquet file: %v", err)
+	}
+	if err := second.WriteToLocalParquet(filepath.Join(baseDir, "nested", "b.parquet")); err != nil {
+		t.Fatalf("Failed to write parquet file: %v", err)
+	}
+
+	readDF, err := ReadFromLocalParquetDir[Student](baseDir)
+	if err != nil {
+		t.Fatalf("Failed to read parquet directory: %v", err)
+	}
+	if len(readDF.Records) != 3 {
+		t.Fatalf("Record count mismatch: expected=3, got=%d", len(readDF.Records))
+	}
+	for i, read := range readDF.Records {
+		if read.Id != int64(i+1) {
+			t.Errorf("Id mismatch at index %d: expected=%d, got=%d", i, i+1, read.Id)
+		}
+	}
+
+	// An empty directory is an error
+	emptyDir := filepath.Join(baseDir, "empty")
+	if err := os.MkdirAll(emptyDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if _, err := ReadFromLocalParquetDir[Student](emptyDir); err == nil {
+		t.Errorf("Expected an error for a directory without parquet files")
+	}
+}
+
+// TestWriteToJSONArray tests writing a DataFrame as a single JSON array
+func TestWriteToJSONArray(t *testing.T) {
+	type TestStudent struct {
+		Name string `json:"name"`
+		Id   int64  `json:"id"`
+	}
+	students := []TestStudent{{Name: "Alice", Id: 1}, {Name: "Bob", Id: 2}, {Name: "Charlie", Id: 3}}
+
+	tempFile := filepath.Join("tmp", "json_array", "test_array.json")
+	defer os.RemoveAll(filepath.Dir(tempFile))
+
+	if err := CreateDataFrame(students).WriteToJSONArray(tempFile); err != nil {
+		t.Fatalf("Failed to write JSON array: %v", err)
+	}
+
+	data, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read JSON array: %v", err)
+	}
+	var read []TestStudent
+	if err := json.Unmarshal(data, &read); err != nil {
+		t.Fatalf("Failed to decode JSON array: %v", err)
+	}
+	if len(read) != len(students) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(students), len(read))
+	}
+	for i := range students {
+		if read[i] != students[i] {
+			t.Errorf("Record mismatch at index %d: original=%+v, read=%+v", i, students[i], read[i])
+		}
+	}
+
+	// An empty DataFrame produces an empty array
+	if err := CreateDataFrame([]TestStudent{}).WriteToJSONArray(tempFile); err != nil {
+		t.Fatalf("Failed to write empty JSON array: %v", err)
+	}
+	data, err = os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read JSON array: %v", err)
+	}
+	if string(data) != "[]" {
+		t.Errorf("Expected empty array, got %q", data)
+	}
+}
+
+// TestReadJSONLFromReader tests reading JSONL from an in-memory reader
+func TestReadJSONLFromReader(t *testing.T) {
+	type TestStudent struct {
+		Name string `json:"name"`
+		Id   int64  `json:"id"`
+	}
+	input := `{"name": "Alice", "id": 1}
+
+{"name": "Bob", "id": 2}
+   
+{"name": "Charlie", "id": 3}
+`
+
+	df, err := ReadJSONLFromReader[TestStudent](strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to read JSONL: %v", err)
+	}
+	if len(df.Records) != 3 {
+		t.Fatalf("Record count mismatch: expected=3, got=%d", len(df.Records))
+	}
+	if df.Records[2].Name != "Charlie" || df.Records[2].Id != 3 {
+		t.Errorf("Unexpected last record: %+v", df.Records[2])
+	}
+
+	// Parse errors report the line number, counting blank lines
+	_, err = ReadJSONLFromReader[TestStudent](strings.NewReader("{\"name\": \"Alice\"}\n\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected a parse error at line 3, got %v", err)
+	}
+}
+
+// TestWriteJSONLToWriter tests writing JSONL to an in-memory buffer
+func TestWriteJSONLToWriter(t *testing.T) {
+	type TestStudent struct {
+		Name string `json:"name"`
+		Id   int64  `json:"id"`
+	}
+	students := []TestStudent{{Name: "Alice", Id: 1}, {Name: "Bob", Id: 2}, {Name: "Charlie", Id: 3}}
+
+	var buf bytes.Buffer
+	if err := WriteJSONLToWriter(&buf, students); err != nil {
+		t.Fatalf("Failed to write JSONL: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(students) {
+		t.Fatalf("Line count mismatch: expected=%d, got=%d", len(students), len(lines))
+	}
+	for i, line := range lines {
+		var read TestStudent
+		if err := json.Unmarshal([]byte(line), &read); err != nil {
+			t.Fatalf("Failed to decode line %d: %v", i+1, err)
+		}
+		if read != students[i] {
+			t.Errorf("Record mismatch at index %d: original=%+v, read=%+v", i, students[i], read)
+		}
+	}
+}
+
+// TestWriteToJSON tests writing a DataFrame as an indented JSON array
+func TestWriteToJSON(t *testing.T) {
+	type TestStudent struct {
+		Name string `json:"name"`
+		Id   int64  `json:"id"`
+	}
+	students := []TestStudent{{Name: "Alice", Id: 1}, {Name: "Bob", Id: 2}}
+
+	tempFile := filepath.Join("tmp", "test_pretty.json")
+	defer os.Remove(tempFile)
+
+	if err := CreateDataFrame(students).WriteToJSON(tempFile); err != nil {
+		t.Fatalf("Failed to write JSON: %v", err)
+	}
+
+	data, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read JSON: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "[\n  {\n    \"name\": \"Alice\",") {
+		t.Errorf("Expected indented JSON, got:\n%s", data)
+	}
+
+	var read []TestStudent
+	if err := json.Unmarshal(data, &read); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	if len(read) != len(students) || read[0] != students[0] || read[1] != students[1] {
+		t.Errorf("Records mismatch: original=%+v, read=%+v", students, read)
+	}
+
+	// A nil slice is written as an empty array
+	if err := CreateDataFrame[TestStudent](nil).WriteToJSON(tempFile); err != nil {
+		t.Fatalf("Failed to write empty JSON: %v", err)
+	}
+	data, err = os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read JSON: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "[]" {
+		t.Errorf("Expected empty array, got %q", data)
+	}
+}
+
+// TestParquetSinks tests writing the same DataFrame to interchangeable sinks
+func TestParquetSinks(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1},
+		{Name: "Bob", Age: 22, Id: 2},
+		{Name: "Charlie", Age: 25, Id: 3},
+	}
+	df := CreateDataFrame(students)
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	tempFile := filepath.Join(dirPath, "test_sink.parquet")
+	defer os.Remove(tempFile)
+
+	bufferSink := &BufferSink{}
+	if bufferSink.Bytes() != nil {
+		t.Errorf("Expected no bytes before writing")
+	}
+
+	sinks := map[string]ParquetSink{
+		"local":  LocalSink{Path: tempFile},
+		"buffer": bufferSink,
+	}
+	for name, sink := range sinks {
+		if err := df.Write(context.Background(), sink, DefaultParquetConfig()); err != nil {
+			t.Fatalf("Failed to write to %s sink: %v", name, err)
+		}
+	}
+
+	fromLocal, err := ReadFromLocalParquet[Student](tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read local sink output: %v", err)
+	}
+	fromBuffer, err := ReadFromParquetBytes[Student](bufferSink.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to read buffer sink output: %v", err)
+	}
+	for name, readDF := range map[string]*DataFrame[Student]{"local": fromLocal, "buffer": fromBuffer} {
+		if len(readDF.Records) != len(students) {
+			t.Fatalf("Record count mismatch for %s sink: expected=%d, got=%d", name, len(students), len(readDF.Records))
+		}
+		for i, record := range readDF.Records {
+			if record != students[i] {
+				t.Errorf("Record mismatch for %s sink at index %d: expected=%+v, got=%+v", name, i, students[i], record)
+			}
+		}
+	}
+
+	// A cancelled write reports the context error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := df.Write(ctx, LocalSink{Path: tempFile}, DefaultParquetConfig()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// Errors opening the sink are returned as is
+	badSink := LocalSink{Path: filepath.Join(dirPath, "missing", "dir", "out.parquet")}
+	if err := df.Write(context.Background(), badSink, DefaultParquetConfig()); err == nil {
+		t.Errorf("Expected error writing to a missing directory")
+	}
+}
+
+// TestParquetEncodingOverrides tests overriding column encodings at write time
+func TestParquetEncodingOverrides(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1, RecordInfo: RecordInfo{RawData: `{"name":"Alice"}`}},
+		{Name: "Alice", Age: 22, Id: 2, RecordInfo: RecordInfo{RawData: `{"name":"Alice"}`}},
+	}
+	df := CreateDataFrame(students)
+
+	hasDictionary := func(encodings []parquet.Encoding) bool {
+		return slices.Contains(encodings, parquet.Encoding_PLAIN_DICTIONARY)
+	}
+
+	// Without overrides the struct tags apply: only name is dictionary encoded
+	data, err := df.ToParquetBytes()
+	if err != nil {
+		t.Fatalf("Failed to write Parquet: %v", err)
+	}
+	metadata, err := ReadParquetMetadata(buffer.NewBufferFileFromBytes(data))
+	if err != nil {
+		t.Fatalf("Failed to read Parquet metadata: %v", err)
+	}
+	if !hasDictionary(metadata.ColumnEncodings["name"]) || hasDictionary(metadata.ColumnEncodings["_recordinfo._raw_data"]) {
+		t.Fatalf("Unexpected default encodings: %v", metadata.ColumnEncodings)
+	}
+
+	config := DefaultParquetConfig()
+	config.Encodings = map[string]parquet.Encoding{
+		"name":                  parquet.Encoding_PLAIN,
+		"_recordinfo._raw_data": parquet.Encoding_PLAIN_DICTIONARY,
+	}
+	data, err = df.ToParquetBytes(config)
+	if err != nil {
+		t.Fatalf("Failed to write Parquet with encoding overrides: %v", err)
+	}
+	metadata, err = ReadParquetMetadata(buffer.NewBufferFileFromBytes(data))
+	if err != nil {
+		t.Fatalf("Failed to read Parquet metadata: %v", err)
+	}
+	if hasDictionary(metadata.ColumnEncodings["name"]) {
+		t.Errorf("Expected dictionary encoding to be disabled for name: %v", metadata.ColumnEncodings["name"])
+	}
+	if !hasDictionary(metadata.ColumnEncodings["_recordinfo._raw_data"]) {
+		t.Errorf("Expected dictionary encoding for _recordinfo._raw_data: %v", metadata.ColumnEncodings["_recordinfo._raw_data"])
+	}
+
+	readDF, err := ReadFromParquetBytes[Student](data)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet: %v", err)
+	}
+	for i, record := range readDF.Records {
+		if record != students[i] {
+			t.Errorf("Record mismatch at index %d: expected=%+v, got=%+v", i, students[i], record)
+		}
+	}
+
+	// Unknown and non-leaf columns are rejected before anything is written
+	for _, column := range []string{"missing", "_recordinfo"} {
+		config.Encodings = map[string]parquet.Encoding{column: parquet.Encoding_PLAIN}
+		if _, err := df.ToParquetBytes(config); err == nil {
+			t.Errorf("Expected error overriding encoding of column '%s'", column)
+		}
+	}
+}
+
+// TestParquetColumnStatistics tests that per-row-group min/max statistics are written to the
+// footer and can be omitted
+func TestParquetColumnStatistics(t *testing.T) {
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Age  int32  `parquet:"name=age, type=INT32"`
+	}
+	students := make([]TestStudent, 20000)
+	for i := range students {
+		students[i] = TestStudent{Name: fmt.Sprintf("student-%d", i), Age: int32(i)}
+	}
+	df := CreateDataFrame(students)
+
+	config := DefaultParquetConfig()
+	config.RowGroupSize = 16 * 1024 // 16KB
+	data, err := df.ToParquetBytes(config)
+	if err != nil {
+		t.Fatalf("Failed to write Parquet: %v", err)
+	}
+	metadata, err := ReadParquetMetadata(buffer.NewBufferFileFromBytes(data))
+	if err != nil {
+		t.Fatalf("Failed to read Parquet metadata: %v", err)
+	}
+	if metadata.NumRowGroups <= 1 {
+		t.Fatalf("Expected more than one row group, got %d", metadata.NumRowGroups)
+	}
+
+	// Ages are sorted, so each row group covers the range that follows the previous one
+	stats := metadata.ColumnStatistics["age"]
+	if len(stats) != metadata.NumRowGroups {
+		t.Fatalf("Expected statistics for %d row groups, got %d", metadata.NumRowGroups, len(stats))
+	}
+	next := int32(0)
+	for g, stat := range stats {
+		if len(stat.GetMinValue()) != 4 || len(stat.GetMaxValue()) != 4 {
+			t.Fatalf("Missing age statistics in row group %d: %+v", g, stat)
+		}
+		minAge := int32(binary.LittleEndian.Uint32(stat.GetMinValue()))
+		maxAge := int32(binary.LittleEndian.Uint32(stat.GetMaxValue()))
+		if minAge != next || maxAge < minAge {
+			t.Errorf("Row group %d age range mismatch: expected min=%d, got min=%d max=%d", g, next, minAge, maxAge)
+		}
+		if stat.GetNullCount() != 0 {
+			t.Errorf("Row group %d null count mismatch: expected=0, got=%d", g, stat.GetNullCount())
+		}
+		next = maxAge + 1
+	}
+	if next != int32(len(students)) {
+		t.Errorf("Expected the last row group to end at age %d, got %d", len(students)-1, next-1)
+	}
+
+	config.OmitStats = true
+	slim, err := df.ToParquetBytes(config)
+	if err != nil {
+		t.Fatalf("Failed to write Parquet without statistics: %v", err)
+	}
+	if len(slim) >= len(data) {
+		t.Errorf("Expected a smaller file without statistics: %d >= %d bytes", len(slim), len(data))
+	}
+	metadata, err = ReadParquetMetadata(buffer.NewBufferFileFromBytes(slim))
+	if err != nil {
+		t.Fatalf("Failed to read Parquet metadata: %v", err)
+	}
+	for column, columnStats := range metadata.ColumnStatistics {
+		for g, stat := range columnStats {
+			if stat.GetMinValue() != nil || stat.GetMaxValue() != nil || stat.IsSetNullCount() {
+				t.Errorf("Expected no statistics for %s in row group %d, got %+v", column, g, stat)
+			}
+		}
+	}
+
+	readDF, err := ReadFromParquetBytes[TestStudent](slim)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet without statistics: %v", err)
+	}
+	if len(readDF.Records) != len(students) || readDF.Records[len(students)-1] != students[len(students)-1] {
+		t.Errorf("Records mismatch after reading a file without statistics")
+	}
+}
+
+// TestConvertCSVToParquet tests converting a CSV file to Parquet in one call
+func TestConvertCSVToParquet(t *testing.T) {
+	type TestStudent struct {
+		Name string `json:"name" parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Age  int32  `json:"age" parquet:"name=age, type=INT32"`
+		Id   int64  `json:"id" parquet:"name=id, type=INT64"`
+	}
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	csvFile := filepath.Join(dirPath, "test_convert.csv")
+	parquetFile := filepath.Join(dirPath, "test_convert_csv.parquet")
+	defer os.Remove(csvFile)
+	defer os.Remove(parquetFile)
+
+	csvData := "name,age,id\nAlice,20,1\n\"Doe, Bob\",22,2\n\"Charlie\n\"\"Chuck\"\"\",25,3\n"
+	if err := os.WriteFile(csvFile, []byte(csvData), 0644); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+
+	if err := ConvertCSVToParquet[TestStudent](csvFile, parquetFile, DefaultParquetConfig()); err != nil {
+		t.Fatalf("Failed to convert CSV to Parquet: %v", err)
+	}
+
+	readDF, err := ReadFromLocalParquet[TestStudent](parquetFile)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet: %v", err)
+	}
+	expected := []TestStudent{
+		{Name: "Alice", Age: 20, Id: 1},
+		{Name: "Doe, Bob", Age: 22, Id: 2},
+		{Name: "Charlie\n\"Chuck\"", Age: 25, Id: 3},
+	}
+	if len(readDF.Records) != len(expected) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(expected), len(readDF.Records))
+	}
+	for i, record := range readDF.Records {
+		if record != expected[i] {
+			t.Errorf("Record mismatch at index %d: expected=%+v, got=%+v", i, expected[i], record)
+		}
+	}
+
+	// The bad age is on line 4 of the file, after a quoted field spanning two lines
+	badData := "name,age,id\n\"Alice\nA.\",20,1\nBob,old,2\n"
+	if err := os.WriteFile(csvFile, []byte(badData), 0644); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+	err = ConvertCSVToParquet[TestStudent](csvFile, parquetFile, DefaultParquetConfig())
+	if err == nil || !strings.Contains(err.Error(), "line 4") || !strings.Contains(err.Error(), "'age'") {
+		t.Errorf("Expected a parse error for column 'age' at line 4, got %v", err)
+	}
+}
+
+// TestConvertJSONLToParquet tests converting a JSONL file to Parquet in one call
+func TestConvertJSONLToParquet(t *testing.T) {
+	type TestStudent struct {
+		Name string `json:"name" parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Age  int32  `json:"age" parquet:"name=age, type=INT32"`
+		Id   int64  `json:"id" parquet:"name=id, type=INT64"`
+	}
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	jsonlFile := filepath.Join(dirPath, "test_convert.jsonl")
+	parquetFile := filepath.Join(dirPath, "test_convert_jsonl.parquet")
+	defer os.Remove(jsonlFile)
+	defer os.Remove(parquetFile)
+
+	jsonlData := `{"name": "Alice", "age": 20, "id": 1}
+{"name": "Bob", "age": 22, "id": 2}
+{"name": "Charlie", "age": 25, "id": 3}
+`
+	if err := os.WriteFile(jsonlFile, []byte(jsonlData), 0644); err != nil {
+		t.Fatalf("Failed to write JSONL: %v", err)
+	}
+
+	if err := ConvertJSONLToParquet[TestStudent](jsonlFile, parquetFile, DefaultParquetConfig()); err != nil {
+		t.Fatalf("Failed to convert JSONL to Parquet: %v", err)
+	}
+
+	metadata, err := ReadLocalParquetMetadata(parquetFile)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet metadata: %v", err)
+	}
+	if metadata.NumRows != 3 {
+		t.Errorf("NumRows mismatch: expected=3, got=%d", metadata.NumRows)
+	}
+	readDF, err := ReadFromLocalParquet[TestStudent](parquetFile)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet: %v", err)
+	}
+	if len(readDF.Records) != 3 || readDF.Records[1] != (TestStudent{Name: "Bob", Age: 22, Id: 2}) {
+		t.Errorf("Unexpected records: %+v", readDF.Records)
+	}
+
+	// Empty input still produces a readable file
+	if err := os.WriteFile(jsonlFile, nil, 0644); err != nil {
+		t.Fatalf("Failed to write JSONL: %v", err)
+	}
+	if err := ConvertJSONLToParquet[TestStudent](jsonlFile, parquetFile, DefaultParquetConfig()); err != nil {
+		t.Fatalf("Failed to convert empty JSONL to Parquet: %v", err)
+	}
+	readDF, err = ReadFromLocalParquet[TestStudent](parquetFile)
+	if err != nil {
+		t.Fatalf("Failed to read empty Parquet: %v", err)
+	}
+	if len(readDF.Records) != 0 {
+		t.Errorf("Expected no records, got %d", len(readDF.Records))
+	}
+
+	// Malformed lines report their line number
+	if err := os.WriteFile(jsonlFile, []byte("{\"name\": \"Alice\"}\nnot json\n"), 0644); err != nil {
+		t.Fatalf("Failed to write JSONL: %v", err)
+	}
+	err = ConvertJSONLToParquet[TestStudent](jsonlFile, parquetFile, DefaultParquetConfig())
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected a parse error at line 2, got %v", err)
+	}
+}
+
+// TestReadFromParquetContext tests reading a Parquet file with a context that is cancelled
+// partway through
+func TestReadFromParquetContext(t *testing.T) {
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+	records := make([]TestStudent, 5*parquetContextBatchSize)
+	for i := range records {
+		records[i] = TestStudent{Name: fmt.Sprintf("student_%d", i), Id: int64(i)}
+	}
+	data, err := CreateDataFrame(records).ToParquetBytes()
+	if err != nil {
+		t.Fatalf("Failed to write Parquet: %v", err)
+	}
+
+	df, err := ReadFromParquetContext[TestStudent](context.Background(), buffer.NewBufferFileFromBytes(data))
+	if err != nil {
+		t.Fatalf("Failed to read Parquet: %v", err)
+	}
+	if !slices.Equal(df.Records, records) {
+		t.Errorf("Records mismatch after reading with a context")
+	}
+
+	// Cancel after the first two batches have been read
+	ctx := &cancelAfterCtx{Context: context.Background(), n: 3}
+	df, err = ReadFromParquetContext[TestStudent](ctx, buffer.NewBufferFileFromBytes(data))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if df != nil {
+		t.Errorf("Expected no DataFrame after cancellation, got %d records", len(df.Records))
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReadFromParquetContext[TestStudent](cancelled, buffer.NewBufferFileFromBytes(data)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled for an already cancelled context, got %v", err)
+	}
+}
+
+// TestReadParquetBatches tests reading a Parquet file in fixed-size batches
+func TestReadParquetBatches(t *testing.T) {
+	students := make([]Student, 10000)
+	for i := range students {
+		students[i] = Student{Name: fmt.Sprintf("Student%d", i), Age: int32(i % 100), Id: int64(i)}
+	}
+	data, err := CreateDataFrame(students).ToParquetBytes()
+	if err != nil {
+		t.Fatalf("Failed to write Parquet: %v", err)
+	}
+
+	var batches, total int
+	var nextID int64
+	err = ReadParquetBatches(buffer.NewBufferFileFromBytes(data), 1000, func(batch []Student) error {
+		batches++
+		total += len(batch)
+		for _, s := range batch {
+			if s.Id != nextID {
+				return fmt.Errorf("expected id %d, got %d", nextID, s.Id)
+			}
+			nextID++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadParquetBatches returned error: %v", err)
+	}
+	if batches != 10 || total != len(students) {
+		t.Errorf("Expected 10 batches and %d rows, got %d batches and %d rows", len(students), batches, total)
+	}
+
+	// A short final batch holds the remainder
+	var sizes []int
+	err = ReadParquetBatches(buffer.NewBufferFileFromBytes(data), 3000, func(batch []Student) error {
+		sizes = append(sizes, len(batch))
+		return nil
+	})
+	if err != nil || fmt.Sprint(sizes) != "[3000 3000 3000 1000]" {
+		t.Errorf("Unexpected batch sizes %v (err=%v)", sizes, err)
+	}
+
+	// The first error from fn stops reading
+	errStop := errors.New("stop")
+	batches = 0
+	err = ReadParquetBatches(buffer.NewBufferFileFromBytes(data), 1000, func(batch []Student) error {
+		batches++
+		if batches == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || batches != 2 {
+		t.Errorf("Expected to stop after 2 batches with errStop, got %d batches (err=%v)", batches, err)
+	}
+
+	if err := ReadParquetBatches(buffer.NewBufferFileFromBytes(data), 0, func([]Student) error { return nil }); err == nil {
+		t.Errorf("Expected error for a zero batch size")
+	}
+}
+
+// TestWriteSlim tests writing records without their RecordInfo metadata
+func TestWriteSlim(t *testing.T) {
+	students := []Student{
+		{Name: "Alice", Age: 20, Id: 1, RecordInfo: RecordInfo{RawData: `{"name":"Alice"}`, RowHash: "abc"}},
+		{Name: "Bob", Age: 22, Id: 2, RecordInfo: RecordInfo{RawData: `{"name":"Bob"}`, RowHash: "def"}},
+	}
+	df := CreateDataFrame(students)
+
+	// Create directory if it doesn't exist
+	dirPath := "tmp"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	jsonlFile := filepath.Join(dirPath, "test_slim.jsonl")
+	parquetFile := filepath.Join(dirPath, "test_slim.parquet")
+	defer os.Remove(jsonlFile)
+	defer os.Remove(parquetFile)
+
+	if err := df.WriteToJSONLSlim(jsonlFile); err != nil {
+		t.Fatalf("Failed to write slim JSONL: %v", err)
+	}
+	content, err := os.ReadFile(jsonlFile)
+	if err != nil {
+		t.Fatalf("Failed to read slim JSONL: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != len(students) {
+		t.Fatalf("Line count mismatch: expected=%d, got=%d", len(students), len(lines))
+	}
+	for i, line := range lines {
+		var row map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			t.Fatalf("Invalid JSON on line %d: %v", i+1, err)
+		}
+		for _, key := range []string{"_recordinfo", "_raw_data", "RecordInfo"} {
+			if _, ok := row[key]; ok {
+				t.Errorf("Unexpected key %q on line %d: %s", key, i+1, line)
+			}
+		}
+		if row["Name"] != students[i].Name {
+			t.Errorf("Name mismatch on line %d: %s", i+1, line)
+		}
+	}
+
+	// The full DataFrame still carries the metadata
+	if students[0].RawData == "" {
+		t.Fatalf("Source records were modified")
+	}
+
+	if err := df.WriteToLocalParquetSlim(parquetFile); err != nil {
+		t.Fatalf("Failed to write slim Parquet: %v", err)
+	}
+	metadata, err := ReadLocalParquetMetadata(parquetFile)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet metadata: %v", err)
+	}
+	if metadata.NumRows != 2 || slices.Contains(metadata.Columns, "_recordinfo") || !slices.Contains(metadata.Columns, "name") {
+		t.Errorf("Unexpected slim Parquet metadata: %+v", metadata)
+	}
+	type SlimStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+	readDF, err := ReadFromLocalParquetColumns[SlimStudent](parquetFile, []string{"name", "id"})
+	if err != nil {
+		t.Fatalf("Failed to read slim Parquet: %v", err)
+	}
+	if readDF.Records[1].Name != "Bob" || readDF.Records[1].Id != 2 {
+		t.Errorf("Unexpected record read back: %+v", readDF.Records[1])
+	}
+}
+
+// TestParquetTimeFields tests that time.Time fields tagged as timestamps round trip through
+// Parquet in their tagged unit
+func TestParquetTimeFields(t *testing.T) {
+	type Event struct {
+		Name      string     `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		CreatedAt time.Time  `parquet:"name=created_at, type=INT64, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MILLIS"`
+		UpdatedAt *time.Time `parquet:"name=updated_at, type=INT64, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MICROS"`
+		Note      time.Time  // Untagged fields are not written
+	}
+	tokyo := time.FixedZone("JST", 9*60*60)
+	created := time.Date(2024, 3, 1, 9, 30, 15, 123456789, tokyo)
+	updated := time.Date(2024, 3, 2, 0, 0, 0, 987654321, time.UTC)
+	events := []Event{
+		{Name: "signup", CreatedAt: created, UpdatedAt: &updated, Note: created},
+		{Name: "login", CreatedAt: created.Add(time.Hour)},
+	}
+
+	data, err := CreateDataFrame(events).ToParquetBytes()
+	if err != nil {
+		t.Fatalf("Failed to write Parquet: %v", err)
+	}
+	readDF, err := ReadFromParquetBytes[Event](data)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet: %v", err)
+	}
+	if len(readDF.Records) != len(events) {
+		t.Fatalf("Record count mismatch: expected=%d, got=%d", len(events), len(readDF.Records))
+	}
+
+	first, second := readDF.Records[0], readDF.Records[1]
+	if !first.CreatedAt.Equal(created.Truncate(time.Millisecond)) || first.CreatedAt.Location() != time.UTC {
+		t.Errorf("CreatedAt mismatch: expected=%v, got=%v", created.Truncate(time.Millisecond).UTC(), first.CreatedAt)
+	}
+	if first.UpdatedAt == nil || !first.UpdatedAt.Equal(updated.Truncate(time.Microsecond)) {
+		t.Errorf("UpdatedAt mismatch: expected=%v, got=%v", updated.Truncate(time.Microsecond), first.UpdatedAt)
+	}
+	if !first.Note.IsZero() {
+		t.Errorf("Expected the untagged Note to be skipped, got %v", first.Note)
+	}
+	if second.Name != "login" || !second.CreatedAt.Equal(created.Add(time.Hour).Truncate(time.Millisecond)) || second.UpdatedAt != nil {
+		t.Errorf("Unexpected second record: %+v", second)
+	}
+
+	// The column holds plain epoch millis, readable without the conversion
+	type RawEvent struct {
+		Name      string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		CreatedAt int64  `parquet:"name=created_at, type=INT64, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MILLIS"`
+	}
+	raw, err := ReadFromParquetColumns[RawEvent](buffer.NewBufferFileFromBytes(data), []string{"name", "created_at"})
+	if err != nil {
+		t.Fatalf("Failed to read raw timestamps: %v", err)
+	}
+	if raw.Records[0].CreatedAt != created.UnixMilli() {
+		t.Errorf("Raw timestamp mismatch: expected=%d, got=%d", created.UnixMilli(), raw.Records[0].CreatedAt)
+	}
+
+	var batched []Event
+	if err := ReadParquetBatches(buffer.NewBufferFileFromBytes(data), 1, func(batch []Event) error {
+		batched = append(batched, batch...)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to read Parquet in batches: %v", err)
+	}
+	if len(batched) != 2 || !batched[0].CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("Unexpected batched records: %+v", batched)
+	}
+
+	// Streaming and lenient writes and column reads convert time fields too
+	stream := make(chan Event, len(events))
+	for _, event := range events {
+		stream <- event
+	}
+	close(stream)
+	streamed := buffer.NewBufferFile()
+	if err := WriteParquetStream(streamed, stream, DefaultParquetConfig()); err != nil {
+		t.Fatalf("Failed to stream Parquet: %v", err)
+	}
+	lenient := buffer.NewBufferFile()
+	if written, errs, err := CreateDataFrame(events).WriteToParquetLenient(lenient, DefaultParquetConfig()); err != nil || written != 2 || len(errs) != 0 {
+		t.Fatalf("Lenient write failed: written=%d, errs=%v, err=%v", written, errs, err)
+	}
+	for name, file := range map[string]*buffer.BufferFile{"stream": streamed, "lenient": lenient} {
+		columnsDF, err := ReadFromParquetColumns[Event](buffer.NewBufferFileFromBytes(file.Bytes()), []string{"created_at", "updated_at"})
+		if err != nil {
+			t.Fatalf("%s: failed to read time columns: %v", name, err)
+		}
+		got := columnsDF.Records[0]
+		if got.Name != "" || !got.CreatedAt.Equal(first.CreatedAt) || got.UpdatedAt == nil || !got.UpdatedAt.Equal(*first.UpdatedAt) {
+			t.Errorf("%s: unexpected record read by column: %+v", name, got)
+		}
+	}
+
+	type BadEvent struct {
+		CreatedAt time.Time `parquet:"name=created_at, type=BYTE_ARRAY, convertedtype=UTF8"`
+	}
+	if _, err := CreateDataFrame([]BadEvent{{}}).ToParquetBytes(); err == nil || !strings.Contains(err.Error(), "CreatedAt") {
+		t.Errorf("Expected an error for a time.Time field not tagged as a timestamp, got %v", err)
+	}
+}
+
+// SourceMeta is embedded by TestParquetEmbeddedMethods; it has a pointer method
+type SourceMeta struct {
+	Source string `parquet:"name=source, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+func (m *SourceMeta) SetSource(source string) {
+	m.Source = source
+}
+
+// TestParquetEmbeddedMethods tests that structs embedding a type with methods still
+// round trip through Parquet when they have no time.Time fields
+func TestParquetEmbeddedMethods(t *testing.T) {
+	type Row struct {
+		SourceMeta `parquet:"name=meta"`
+		Id         int64 `parquet:"name=id, type=INT64"`
+	}
+	rows := []Row{{Id: 1}, {Id: 2}}
+	rows[0].SetSource("api")
+	rows[1].SetSource("file")
+
+	data, err := CreateDataFrame(rows).ToParquetBytes()
+	if err != nil {
+		t.Fatalf("Failed to write Parquet: %v", err)
+	}
+	readDF, err := ReadFromParquetBytes[Row](data)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet: %v", err)
+	}
+	if len(readDF.Records) != 2 || readDF.Records[0] != rows[0] || readDF.Records[1] != rows[1] {
+		t.Errorf("Round trip mismatch: expected=%+v, got=%+v", rows, readDF.Records)
+	}
+}
+
+// TestParquetNullPointers tests that nil pointer fields round-trip as nil rather than zero
+func TestParquetNullPointers(t *testing.T) {
+	zero, five := int32(0), int32(5)
+	students := []Student{
+		{Name: "Alice", Id: 1},
+		{Name: "Bob", Id: 2, Ignored: &zero},
+		{Name: "Charlie", Id: 3, Ignored: &five},
+		{Name: "Dave", Id: 4},
+	}
+	data, err := CreateDataFrame(students).ToParquetBytes()
+	if err != nil {
+		t.Fatalf("Failed to write Parquet: %v", err)
+	}
+
+	checkIgnored := func(name string, records []Student) {
+		t.Helper()
+		if len(records) != len(students) {
+			t.Fatalf("%s: record count mismatch: expected=%d, got=%d", name, len(students), len(records))
+		}
+		for i, record := range records {
+			want := students[i].Ignored
+			if (want == nil) != (record.Ignored == nil) || (want != nil && *want != *record.Ignored) {
+				t.Errorf("%s: Ignored mismatch at index %d: expected=%v, got=%v", name, i, want, record.Ignored)
+			}
+		}
+	}
+
+	readDF, err := ReadFromParquetBytes[Student](data)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet: %v", err)
+	}
+	checkIgnored("ReadFromParquet", readDF.Records)
+
+	columnsDF, err := ReadFromParquetColumns[Student](buffer.NewBufferFileFromBytes(data), []string{"name", "ignored"})
+	if err != nil {
+		t.Fatalf("Failed to read Parquet columns: %v", err)
+	}
+	checkIgnored("ReadFromParquetColumns", columnsDF.Records)
+
+	var batched []Student
+	err = ReadParquetBatches(buffer.NewBufferFileFromBytes(data), 3, func(batch []Student) error {
+		batched = append(batched, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to read Parquet batches: %v", err)
+	}
+	checkIgnored("ReadParquetBatches", batched)
+}
+
 // TestS3Parquet tests writing to and reading from an S3-compatible storage (MinIO)
 func TestS3Parquet(t *testing.T) {
 	if testing.Short() {
@@ -373,6 +3382,338 @@ func TestS3Parquet(t *testing.T) {
 	t.Logf("Successfully verified %d records from S3", len(readDF.Records))
 }
 
+// TestS3ParquetWriteOptions tests writing to S3 with a non-default ACL and content type
+func TestS3ParquetWriteOptions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping S3 test in short mode")
+	}
+
+	// Setup MinIO
+	bucketName, _, s3Client, cleanup := setupMinioS3(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	keyName := "test-data/students-acl.parquet"
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+	students := []TestStudent{{Name: "Alice", Id: 1001}, {Name: "Bob", Id: 1002}}
+
+	opts := S3WriteOptions{ACL: awsS3.ObjectCannedACLBucketOwnerFullControl}
+	if err := CreateDataFrame(students).WriteToS3ParquetWithOptions(ctx, s3Client, bucketName, keyName, opts); err != nil {
+		t.Fatalf("Failed to write to S3 with options: %v", err)
+	}
+
+	head, err := s3Client.HeadObject(&awsS3.HeadObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(keyName),
+	})
+	if err != nil {
+		t.Fatalf("File was not written or not accessible: %v", err)
+	}
+	if aws.StringValue(head.ContentType) != ParquetContentType {
+		t.Errorf("ContentType mismatch: expected=%s, got=%s", ParquetContentType, aws.StringValue(head.ContentType))
+	}
+
+	readDF, err := ReadFromS3Parquet[TestStudent](ctx, s3Client, bucketName, keyName)
+	if err != nil {
+		t.Fatalf("Failed to read from S3: %v", err)
+	}
+	if len(readDF.Records) != len(students) {
+		t.Errorf("Record count mismatch: expected=%d, got=%d", len(students), len(readDF.Records))
+	}
+}
+
+// TestS3JSONL tests streaming JSONL to S3 and reading it back line by line
+func TestS3JSONL(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping S3 test in short mode")
+	}
+
+	// Setup MinIO
+	bucketName, _, s3Client, cleanup := setupMinioS3(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	keyName := "test-data/students.jsonl"
+	type TestStudent struct {
+		Name string `json:"name"`
+		Id   int64  `json:"id"`
+	}
+	students := []TestStudent{{Name: "Alice", Id: 1001}, {Name: "Bob", Id: 1002}}
+
+	if err := CreateDataFrame(students).WriteToS3JSONL(ctx, s3Client, bucketName, keyName); err != nil {
+		t.Fatalf("Failed to write JSONL to S3: %v", err)
+	}
+
+	obj, err := s3Client.GetObject(&awsS3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(keyName),
+	})
+	if err != nil {
+		t.Fatalf("File was not written or not accessible: %v", err)
+	}
+	defer obj.Body.Close()
+	if aws.StringValue(obj.ContentType) != JSONLContentType {
+		t.Errorf("ContentType mismatch: expected=%s, got=%s", JSONLContentType, aws.StringValue(obj.ContentType))
+	}
+
+	scanner := bufio.NewScanner(obj.Body)
+	var lines int
+	for scanner.Scan() {
+		var got TestStudent
+		if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+			t.Fatalf("Failed to parse line %d: %v", lines+1, err)
+		}
+		if lines >= len(students) || got != students[lines] {
+			t.Errorf("Line %d mismatch: got=%+v", lines+1, got)
+		}
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to read JSONL from S3: %v", err)
+	}
+	if lines != len(students) {
+		t.Errorf("Line count mismatch: expected=%d, got=%d", len(students), lines)
+	}
+}
+
+// TestReadFromS3JSONL tests a JSONL round trip through S3, including a gzipped object
+func TestReadFromS3JSONL(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping S3 test in short mode")
+	}
+
+	// Setup MinIO
+	bucketName, _, s3Client, cleanup := setupMinioS3(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	type TestStudent struct {
+		Name string `json:"name"`
+		Id   int64  `json:"id"`
+	}
+	students := []TestStudent{{Name: "Alice", Id: 1001}, {Name: "Bob", Id: 1002}}
+
+	keyName := "test-data/students.jsonl"
+	if err := CreateDataFrame(students).WriteToS3JSONL(ctx, s3Client, bucketName, keyName); err != nil {
+		t.Fatalf("Failed to write JSONL to S3: %v", err)
+	}
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if err := WriteJSONLToWriter(gz, students); err != nil {
+		t.Fatalf("Failed to encode JSONL: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+	gzKeyName := "test-data/students.jsonl.gz"
+	if _, err := s3Client.PutObject(&awsS3.PutObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(gzKeyName),
+		Body:   bytes.NewReader(compressed.Bytes()),
+	}); err != nil {
+		t.Fatalf("Failed to upload gzipped JSONL: %v", err)
+	}
+
+	for _, key := range []string{keyName, gzKeyName} {
+		readDF, err := ReadFromS3JSONL[TestStudent](ctx, s3Client, bucketName, key)
+		if err != nil {
+			t.Fatalf("Failed to read %s from S3: %v", key, err)
+		}
+		if !slices.Equal(readDF.Records, students) {
+			t.Errorf("Records mismatch for %s: expected=%+v, got=%+v", key, students, readDF.Records)
+		}
+	}
+
+	if _, err := ReadFromS3JSONL[TestStudent](ctx, s3Client, bucketName, "test-data/missing.jsonl"); err == nil {
+		t.Error("Expected an error for a missing key")
+	}
+}
+
+// TestS3ParquetPrefix tests reading all Parquet objects under an S3 prefix
+func TestS3ParquetPrefix(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping S3 test in short mode")
+	}
+
+	// Setup MinIO
+	bucketName, _, s3Client, cleanup := setupMinioS3(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+
+	// Write three objects under the prefix and one outside it
+	keys := []string{"batch/part-0.parquet", "batch/part-1.parquet", "batch/part-2.parquet", "other/part-0.parquet"}
+	for i, key := range keys {
+		df := CreateDataFrame([]TestStudent{{Name: fmt.Sprintf("student_%d", i), Id: int64(i)}})
+		if err := df.WriteToS3Parquet(ctx, s3Client, bucketName, key); err != nil {
+			t.Fatalf("Failed to write %s to S3: %v", key, err)
+		}
+	}
+
+	readDF, err := ReadFromS3ParquetPrefix[TestStudent](ctx, s3Client, bucketName, "batch/")
+	if err != nil {
+		t.Fatalf("Failed to read prefix from S3: %v", err)
+	}
+	if len(readDF.Records) != 3 {
+		t.Fatalf("Record count mismatch: expected=3, got=%d", len(readDF.Records))
+	}
+	for i, read := range readDF.Records {
+		if read.Id != int64(i) {
+			t.Errorf("Id mismatch at index %d: expected=%d, got=%d", i, i, read.Id)
+		}
+	}
+}
+
+// newFlakyS3Server starts an in-memory S3 stand-in that fails every other request with a 500
+func newFlakyS3Server(t *testing.T) (*httptest.Server, *awsS3.S3) {
+	var (
+		mu       sync.Mutex
+		requests int
+		objects  = make(map[string][]byte)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests++
+		if requests%2 == 1 {
+			io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = body
+		case http.MethodGet, http.MethodHead:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+		}
+	}))
+
+	s3Session, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials("test", "test", ""),
+		Endpoint:         aws.String(server.URL),
+		Region:           aws.String("us-east-1"),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatalf("Could not create S3 session: %v", err)
+	}
+	return server, awsS3.New(s3Session)
+}
+
+// TestS3Retry tests that transient S3 failures are retried when retries are enabled
+func TestS3Retry(t *testing.T) {
+	server, s3Client := newFlakyS3Server(t)
+	defer server.Close()
+
+	ctx := context.Background()
+	keyName := "test-data/students-retry.parquet"
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+	df := CreateDataFrame([]TestStudent{{Name: "Alice", Id: 1001}, {Name: "Bob", Id: 1002}})
+
+	// Without retries the first injected failure is fatal
+	noRetry := WithS3Retry(s3Client, S3RetryOptions{MaxRetries: 0})
+	if err := df.WriteToS3Parquet(ctx, noRetry, "bucket", keyName); err == nil {
+		t.Fatal("Expected write to fail without retries")
+	}
+
+	retrying := WithS3Retry(s3Client, S3RetryOptions{MaxRetries: 3, BaseDelay: time.Millisecond})
+	if err := df.WriteToS3Parquet(ctx, retrying, "bucket", keyName); err != nil {
+		t.Fatalf("Failed to write to S3 with retries: %v", err)
+	}
+
+	readDF, err := ReadFromS3Parquet[TestStudent](ctx, retrying, "bucket", keyName)
+	if err != nil {
+		t.Fatalf("Failed to read from S3 with retries: %v", err)
+	}
+	if len(readDF.Records) != 2 || readDF.Records[1].Name != "Bob" {
+		t.Errorf("Unexpected records read back: %+v", readDF.Records)
+	}
+}
+
+// TestS3ParquetMultipart tests writing an object large enough to need a multipart upload
+func TestS3ParquetMultipart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping S3 test in short mode")
+	}
+
+	// Setup MinIO
+	bucketName, _, s3Client, cleanup := setupMinioS3(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	keyName := "test-data/students-multipart.parquet"
+	type TestStudent struct {
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Id   int64  `parquet:"name=id, type=INT64"`
+	}
+
+	// Roughly 12MB of uncompressed, non-repeating data spans three 5MB parts
+	padding := strings.Repeat("x", 100)
+	students := make([]TestStudent, 120000)
+	for i := range students {
+		students[i] = TestStudent{Name: fmt.Sprintf("%s_%d", padding, i), Id: int64(i)}
+	}
+	df := CreateDataFrame(students)
+	config := DefaultParquetConfig()
+	config.Compression = parquet.CompressionCodec_UNCOMPRESSED
+
+	opts := S3WriteOptions{PartSize: 5 * 1024 * 1024}
+	if err := df.WriteToS3ParquetWithOptions(ctx, s3Client, bucketName, keyName, opts, config); err != nil {
+		t.Fatalf("Failed to write to S3: %v", err)
+	}
+
+	// Write the same frame locally to get the expected size
+	localFile := filepath.Join("tmp", "test_multipart.parquet")
+	defer os.Remove(localFile)
+	if err := df.WriteToLocalParquet(localFile, config); err != nil {
+		t.Fatalf("Failed to write parquet file: %v", err)
+	}
+	info, err := os.Stat(localFile)
+	if err != nil {
+		t.Fatalf("Failed to stat parquet file: %v", err)
+	}
+
+	head, err := s3Client.HeadObject(&awsS3.HeadObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(keyName),
+	})
+	if err != nil {
+		t.Fatalf("File was not written or not accessible: %v", err)
+	}
+	if aws.Int64Value(head.ContentLength) != info.Size() {
+		t.Errorf("Object size mismatch: expected=%d, got=%d", info.Size(), aws.Int64Value(head.ContentLength))
+	}
+	// Multipart ETags carry a "-<parts>" suffix
+	if !strings.Contains(aws.StringValue(head.ETag), "-") {
+		t.Errorf("Expected a multipart ETag, got %s", aws.StringValue(head.ETag))
+	}
+
+	// Part sizes below the S3 minimum are rejected
+	if err := df.WriteToS3ParquetWithOptions(ctx, s3Client, bucketName, keyName, S3WriteOptions{PartSize: 1024}); err == nil {
+		t.Errorf("Expected an error for a part size below 5MB")
+	}
+}
+
 // setupMinioS3 creates a MinIO container and configures it for testing
 // Returns: bucketName, minioURL, s3Client, cleanup function
 func setupMinioS3(t *testing.T) (string, string, *awsS3.S3, func()) {
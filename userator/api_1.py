@@ -182,6 +182,21 @@ def get_users(
         return [UserResponse.model_validate(dict(row)) for row in users_rows]
 
 
+class UserCount(BaseModel):
+    count: int
+
+
+# Total number of users, used by clients to plan concurrent pagination.
+# Declared before /users/{user_id_path} so "count" isn't parsed as a user ID.
+@app.get("/users/count", response_model=UserCount, tags=["Users"])
+def count_users(db: sqlite3.Connection = Depends(get_db_connection)):
+    """
+    Return the total number of users.
+    """
+    cursor = db.execute("SELECT COUNT(*) FROM users")
+    return UserCount(count=cursor.fetchone()[0])
+
+
 # If you want a separate endpoint for getting a user by ID (more RESTful):
 @app.get("/users/{user_id_path}", response_model=UserResponse, tags=["Users"])
 def get_user_by_id(
@@ -194,6 +194,18 @@ def test_get_all_users(client: TestClient, test_db_conn: sqlite3.Connection):
     assert response_emails == sample_emails
 
 
+def test_count_users(client: TestClient, test_db_conn: sqlite3.Connection):
+    """Positive case - count users, before and after adding some"""
+    response = client.get("/users/count")
+    assert response.status_code == 200
+    assert response.json() == {"count": 0}
+
+    sample_users = _add_sample_users(test_db_conn)
+    response = client.get("/users/count")
+    assert response.status_code == 200
+    assert response.json() == {"count": len(sample_users)}
+
+
 def test_get_specific_user_by_id_path(
     client: TestClient, test_db_conn: sqlite3.Connection
 ):